@@ -48,8 +48,23 @@ type Build struct {
 }
 
 // Success signals a successful build by exiting with a zero status code.  Combines specied build plan with build
-// plan entries for all contributed dependencies.
+// plan entries for all contributed dependencies.  Reports the sizes of contributed layers, failing if they exceed a
+// size budget that is configured to fail.  In plan mode, the planned actions are reported and nothing is written.
 func (b Build) Success(buildPlan buildplan.BuildPlan) (int, error) {
+	if b.Layers.Plan != nil {
+		if err := b.Layers.TouchedLayers.Cleanup(); err != nil {
+			return -1, err
+		}
+
+		b.Layers.Plan.Report(b.Logger)
+		b.Logger.Info("")
+		return build.SuccessStatusCode, nil
+	}
+
+	if err := b.Layers.Summary.Report(); err != nil {
+		return -1, err
+	}
+
 	bp := buildplan.BuildPlan{}
 	bp.Merge(b.Layers.DependencyBuildPlans, buildPlan)
 
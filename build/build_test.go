@@ -24,6 +24,7 @@ import (
 	"github.com/buildpack/libbuildpack/buildplan"
 	"github.com/heroku/libhkbuildpack/build"
 	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/layers"
 	"github.com/heroku/libhkbuildpack/test"
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
@@ -112,6 +113,28 @@ test-key = "test-value"
 `))
 		})
 
+		it("does not write build plan in plan mode", func() {
+			defer internal.ReplaceWorkingDirectory(t, root)()
+			defer test.ReplaceEnv(t, "CNB_STACK_ID", "test-stack")()
+			defer test.ReplaceEnv(t, layers.PlanEnv, "true")()
+			defer internal.ReplaceArgs(t, filepath.Join(root, "bin", "test"), filepath.Join(root, "layers"), filepath.Join(root, "platform"), filepath.Join(root, "plan.toml"))()
+
+			console, d := internal.ReplaceConsole(t)
+			defer d()
+
+			test.TouchFile(t, root, "buildpack.toml")
+			console.In(t, "")
+
+			b, err := build.DefaultBuild()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(b.Success(buildplan.BuildPlan{
+				"alpha": buildplan.Dependency{Version: "test-version"},
+			})).To(Equal(build.SuccessStatusCode))
+
+			g.Expect(filepath.Join(root, "plan.toml")).NotTo(BeAnExistingFile())
+		})
+
 		it("returns code when failing", func() {
 			defer internal.ReplaceWorkingDirectory(t, root)()
 			defer test.ReplaceEnv(t, "CNB_STACK_ID", "test-stack")()
@@ -113,14 +113,19 @@ func (b Buildpack) PrePackage() (string, bool) {
 	return p, ok
 }
 
+// RuntimeDependency returns the best dependency for an id, version, and stack.  If the version is not specified ("" or
+// "default"), the default version is returned.
 func (b Buildpack) RuntimeDependency(id, version string, stack stack.Stack) (Dependency, error) {
-	var err error
+	resolver := Resolver{}
 
-	if version == "" || version == "default" {
-		version, err = b.DefaultVersion(id)
+	if version == "" || version == DefaultConstraint {
+		d, err := b.DefaultVersion(id)
 		if err != nil {
 			return Dependency{}, err
 		}
+
+		resolver.Defaults = map[string]string{id: d}
+		version = ""
 	}
 
 	deps, err := b.Dependencies()
@@ -128,7 +133,19 @@ func (b Buildpack) RuntimeDependency(id, version string, stack stack.Stack) (Dep
 		return Dependency{}, err
 	}
 
-	return deps.Best(id, version, stack)
+	r, err := resolver.Resolve(deps, id, version, stack)
+	if err != nil {
+		if e, ok := err.(ResolutionError); ok {
+			for _, s := range e.Explanation() {
+				b.logger.Debug("Rejected %s", s)
+			}
+		}
+
+		return Dependency{}, err
+	}
+
+	b.logger.Debug("Resolved %s %s to %s", id, r.Constraint, r.Dependency.Version.Original())
+	return r.Dependency, nil
 }
 
 // String makes Buildpack satisfy the Stringer interface.
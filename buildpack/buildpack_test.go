@@ -260,6 +260,30 @@ func TestBuildpack(t *testing.T) {
 				g.Expect(err).NotTo(HaveOccurred())
 			})
 
+			it("get exactly the DefaultVersion when a later patch exists", func() {
+				patch := make(map[string]interface{})
+				for k, v := range TestDep1 {
+					patch[k] = v
+				}
+				patch["version"] = "1.0.1"
+
+				b := bp.Buildpack{
+					Metadata: bp.Metadata{
+						buildpack.DefaultVersions: map[string]interface{}{
+							id: "1.0.0",
+						},
+						buildpack.DependenciesMetadata: []map[string]interface{}{
+							TestDep1,
+							patch,
+						},
+					},
+				}
+
+				dep, err := buildpack.NewBuildpack(b, logger.New(nil)).RuntimeDependency(id, "", stack)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(dep).To(Equal(expectedDep))
+			})
+
 			it("returns an error if the Dependency is not present in the Buildpack", func() {
 				b := bp.Buildpack{
 					Metadata: bp.Metadata{
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Checksum is a digest of a dependency artifact in the form <algorithm>:<hex>.
+type Checksum string
+
+type algorithm struct {
+	new      func() hash.Hash
+	strength int
+}
+
+// algorithms are the supported checksum algorithms.  Algorithms with a strength of zero are not considered strong
+// enough to verify a dependency on their own.
+var algorithms = map[string]algorithm{
+	"md5":    {md5.New, 0},
+	"sha1":   {sha1.New, 0},
+	"sha256": {sha256.New, 256},
+	"sha384": {sha512.New384, 384},
+	"sha512": {sha512.New, 512},
+}
+
+// NewChecksum creates a Checksum from an algorithm and a hex encoded digest.
+func NewChecksum(algorithm string, digest string) Checksum {
+	return Checksum(fmt.Sprintf("%s:%s", algorithm, digest))
+}
+
+// Algorithm returns the normalized algorithm of the checksum.
+func (c Checksum) Algorithm() string {
+	if i := strings.Index(string(c), ":"); i >= 0 {
+		return strings.ToLower(string(c)[:i])
+	}
+
+	return ""
+}
+
+// Hex returns the hex encoded digest of the checksum.
+func (c Checksum) Hex() string {
+	if i := strings.Index(string(c), ":"); i >= 0 {
+		return strings.ToLower(string(c)[i+1:])
+	}
+
+	return string(c)
+}
+
+// Hash returns a new hash.Hash for the checksum's algorithm.
+func (c Checksum) Hash() (hash.Hash, error) {
+	a, ok := algorithms[c.Algorithm()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", c.Algorithm())
+	}
+
+	return a.new(), nil
+}
+
+// IsStrong returns whether the checksum's algorithm is strong enough to verify a dependency.
+func (c Checksum) IsStrong() bool {
+	return algorithms[c.Algorithm()].strength > 0
+}
+
+// Validate ensures that the checksum has a supported algorithm and a hex encoded digest of the algorithm's size.
+func (c Checksum) Validate() error {
+	if c.Algorithm() == "" {
+		return fmt.Errorf("checksum %q must be in the form <algorithm>:<hex>", c)
+	}
+
+	if _, ok := algorithms[c.Algorithm()]; !ok {
+		return fmt.Errorf("unsupported checksum algorithm %q", c.Algorithm())
+	}
+
+	return validateDigest(c.Algorithm(), c.Hex())
+}
+
+// validateDigest ensures that digest is a hex encoded digest of exactly the size produced by algorithm.
+func validateDigest(algorithm string, digest string) error {
+	size := algorithms[algorithm].new().Size()
+
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != size {
+		return fmt.Errorf("%s %q must be %d hex characters", algorithm, digest, size*2)
+	}
+
+	return nil
+}
+
+func (c Checksum) strength() int {
+	return algorithms[c.Algorithm()].strength
+}
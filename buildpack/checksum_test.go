@@ -0,0 +1,69 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack_test
+
+import (
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestChecksum(t *testing.T) {
+	spec.Run(t, "Checksum", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("parses algorithm and digest", func() {
+			c := buildpack.Checksum("SHA512:ABCDEF")
+
+			g.Expect(c.Algorithm()).To(Equal("sha512"))
+			g.Expect(c.Hex()).To(Equal("abcdef"))
+		})
+
+		it("identifies strong algorithms", func() {
+			g.Expect(buildpack.Checksum("sha256:abcdef").IsStrong()).To(BeTrue())
+			g.Expect(buildpack.Checksum("sha384:abcdef").IsStrong()).To(BeTrue())
+			g.Expect(buildpack.Checksum("sha512:abcdef").IsStrong()).To(BeTrue())
+			g.Expect(buildpack.Checksum("sha1:abcdef").IsStrong()).To(BeFalse())
+			g.Expect(buildpack.Checksum("md5:abcdef").IsStrong()).To(BeFalse())
+		})
+
+		it("validates", func() {
+			g.Expect(buildpack.Checksum("sha512:41ee5b304e3896fd496bf0193d9f2b5cc4ba74e740bfb0e33c7b9d6e8b6a49d9983586095a3c377bd2447f1f39acb6fcd8f83c95a0d7c3ef7050f32e2c29db77").Validate()).To(Succeed())
+		})
+
+		it("does not validate without an algorithm", func() {
+			g.Expect(buildpack.Checksum("abcdef").Validate()).NotTo(Succeed())
+		})
+
+		it("does not validate with an unsupported algorithm", func() {
+			g.Expect(buildpack.Checksum("crc32:abcdef").Validate()).To(MatchError(`unsupported checksum algorithm "crc32"`))
+		})
+
+		it("does not validate with an invalid digest", func() {
+			g.Expect(buildpack.Checksum("sha512:xyz").Validate()).NotTo(Succeed())
+			g.Expect(buildpack.Checksum("sha512:").Validate()).NotTo(Succeed())
+		})
+
+		it("does not validate with a truncated digest", func() {
+			g.Expect(buildpack.Checksum("sha256:abcd").Validate()).To(MatchError(`sha256 "abcd" must be 64 hex characters`))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -17,11 +17,6 @@
 package buildpack
 
 import (
-	"fmt"
-	"sort"
-	"strings"
-
-	"github.com/Masterminds/semver"
 	"github.com/buildpack/libbuildpack/stack"
 )
 
@@ -30,35 +25,15 @@ type Dependencies []Dependency
 
 // Best returns the best (latest version) dependency within a collection of Dependencies.  The candidate set is first
 // filtered by id, version, and stack, then the remaining candidates are sorted for the best result.  If the
-// versionConstraint is not specified (""), then the latest wildcard ("*") is used.
+// versionConstraint is not specified (""), then the latest wildcard ("*") is used.  Use a Resolver for pre-release,
+// default, and pinned version rules, and for an explanation of rejected candidates.
 func (d Dependencies) Best(id string, versionConstraint string, stack stack.Stack) (Dependency, error) {
-	var candidates Dependencies
-
-	vc := versionConstraint
-	if vc == "" {
-		vc = "*"
-	}
-
-	constraint, err := semver.NewConstraint(vc)
+	r, err := Resolver{}.Resolve(d, id, versionConstraint, stack)
 	if err != nil {
 		return Dependency{}, err
 	}
 
-	for _, c := range d {
-		if c.ID == id && constraint.Check(c.Version.Version) && c.Stacks.contains(stack) {
-			candidates = append(candidates, c)
-		}
-	}
-
-	if len(candidates) == 0 {
-		return Dependency{}, fmt.Errorf("no valid dependencies for %s, %s, and %s in [%s]", id, vc, stack, d.candidateMessage())
-	}
-
-	sort.Slice(candidates, func(i int, j int) bool {
-		return d[i].Version.LessThan(d[j].Version.Version)
-	})
-
-	return candidates[len(candidates)-1], nil
+	return r.Dependency, nil
 }
 
 // Has indicates whether the collection of dependencies has any dependency of a specific id.  This is used primarily to
@@ -72,13 +47,3 @@ func (d Dependencies) Has(id string) bool {
 
 	return false
 }
-
-func (d Dependencies) candidateMessage() string {
-	var s []string
-
-	for _, c := range d {
-		s = append(s, fmt.Sprintf("(%s, %s, %s)", c.ID, c.Version.Original(), c.Stacks))
-	}
-
-	return strings.Join(s, ", ")
-}
@@ -147,6 +147,34 @@ func TestDependencies(t *testing.T) {
 			g.Expect(d.Best("test-id", "1.*", "test-stack-1")).To(Equal(expected))
 		})
 
+		it("returns the latest version when candidates are filtered", func() {
+			d := buildpack.Dependencies{
+				buildpack.Dependency{
+					ID:      "test-id-2",
+					Name:    "test-name",
+					Version: internal.NewTestVersion(t, "1.0"),
+					URI:     "test-uri",
+					SHA256:  "test-sha256",
+					Stacks:  buildpack.Stacks{"test-stack-1"}},
+				buildpack.Dependency{
+					ID:      "test-id",
+					Name:    "test-name",
+					Version: internal.NewTestVersion(t, "2.0"),
+					URI:     "test-uri",
+					SHA256:  "test-sha256",
+					Stacks:  buildpack.Stacks{"test-stack-1"}},
+				buildpack.Dependency{
+					ID:      "test-id",
+					Name:    "test-name",
+					Version: internal.NewTestVersion(t, "1.0"),
+					URI:     "test-uri",
+					SHA256:  "test-sha256",
+					Stacks:  buildpack.Stacks{"test-stack-1"}},
+			}
+
+			g.Expect(d.Best("test-id", "", "test-stack-1")).To(Equal(d[1]))
+		})
+
 		it("returns error if there are no matching dependencies", func() {
 			d := buildpack.Dependencies{
 				buildpack.Dependency{
@@ -200,6 +228,21 @@ func TestDependencies(t *testing.T) {
 			g.Expect(d.Best("test-id", "", "test-stack-1")).To(Equal(expected))
 		})
 
+		it("returns error for default version constraint", func() {
+			d := buildpack.Dependencies{
+				buildpack.Dependency{
+					ID:      "test-id",
+					Name:    "test-name",
+					Version: internal.NewTestVersion(t, "1.1"),
+					URI:     "test-uri",
+					SHA256:  "test-sha256",
+					Stacks:  buildpack.Stacks{"test-stack-1", "test-stack-2"}},
+			}
+
+			_, err := d.Best("test-id", "default", "test-stack-1")
+			g.Expect(err).To(HaveOccurred())
+		})
+
 		it("indicates that dependency exists", func() {
 			d := buildpack.Dependencies{
 				buildpack.Dependency{
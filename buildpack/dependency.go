@@ -39,11 +39,33 @@ type Dependency struct {
 	// SHA256 is the hash of the dependency.
 	SHA256 string `mapstruct:"sha256" toml:"sha256"`
 
+	// SHA512 is the SHA-512 hash of the dependency.
+	SHA512 string `mapstruct:"sha512" toml:"sha512,omitempty"`
+
+	// Checksum is an additional hash of the dependency in the form <algorithm>:<hex>.
+	Checksum Checksum `mapstruct:"checksum" toml:"checksum,omitempty"`
+
+	// Signature is an optional detached signature of the dependency.
+	Signature *Signature `mapstruct:"signature" toml:"signature,omitempty"`
+
 	// Stacks are the stacks the dependency is compatible with.
 	Stacks Stacks `mapstruct:"stacks" toml:"stacks"`
 
 	// Licenses are the stacks the dependency is distributed under.
 	Licenses Licenses `mapstruct:"licenses" toml:"licenses"`
+
+	// DeprecationDate is the date, in YYYY-MM-DD form, on which the dependency is deprecated.
+	DeprecationDate string `mapstruct:"deprecation_date" toml:"deprecation_date,omitempty"`
+
+	// EOLDate is the date, in YYYY-MM-DD form, on which the dependency reaches end-of-life.
+	EOLDate string `mapstruct:"eol_date" toml:"eol_date,omitempty"`
+
+	// PURL is the package URL (e.g. pkg:generic/openjdk@11.0.2) that identifies the dependency to vulnerability
+	// scanners.
+	PURL string `mapstruct:"purl" toml:"purl,omitempty"`
+
+	// CPEs are the Common Platform Enumeration names of the dependency.
+	CPEs []string `mapstruct:"cpes" toml:"cpes,omitempty"`
 }
 
 // NewDependency makes a Dependency from a generic map describing a Dependency
@@ -51,8 +73,9 @@ func NewDependency(dep map[string]interface{}) (Dependency, error) {
 	var d Dependency
 
 	config := mapstructure.DecoderConfig{
-		DecodeHook: unmarshalText,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(unmarshalText, unmarshalDate),
 		Result:     &d,
+		TagName:    "mapstruct",
 	}
 
 	decoder, err := mapstructure.NewDecoder(&config)
@@ -67,6 +90,53 @@ func NewDependency(dep map[string]interface{}) (Dependency, error) {
 	return d, nil
 }
 
+// CacheKey returns the key used to store the dependency's artifact in a cache.  This is the SHA256 hash if one is
+// declared, otherwise the digest of the strongest checksum.
+func (d Dependency) CacheKey() string {
+	if d.SHA256 != "" {
+		return d.SHA256
+	}
+
+	if c, ok := d.StrongestChecksum(); ok {
+		return c.Hex()
+	}
+
+	return ""
+}
+
+// Checksums returns all of the checksums declared by the dependency.
+func (d Dependency) Checksums() []Checksum {
+	var c []Checksum
+
+	if d.SHA256 != "" {
+		c = append(c, NewChecksum("sha256", d.SHA256))
+	}
+
+	if d.SHA512 != "" {
+		c = append(c, NewChecksum("sha512", d.SHA512))
+	}
+
+	if d.Checksum != "" {
+		c = append(c, d.Checksum)
+	}
+
+	return c
+}
+
+// StrongestChecksum returns the strongest checksum declared by the dependency and true if any strong checksum is
+// declared.
+func (d Dependency) StrongestChecksum() (Checksum, bool) {
+	var strongest Checksum
+
+	for _, c := range d.Checksums() {
+		if c.IsStrong() && c.strength() > strongest.strength() {
+			strongest = c
+		}
+	}
+
+	return strongest, strongest != ""
+}
+
 // Identity make Buildpack satisfy the Identifiable interface.
 func (d Dependency) Identity() (string, string) {
 	if d.Version.Version != nil {
@@ -76,6 +146,14 @@ func (d Dependency) Identity() (string, string) {
 	return d.Name, ""
 }
 
+// String makes Dependency satisfy the Stringer interface.
+func (d Dependency) String() string {
+	return fmt.Sprintf("Dependency{ ID: %s, Name: %s, Version: %s, URI: %s, SHA256: %s, SHA512: %s, Checksum: %s, "+
+		"Signature: %v, Stacks: %s, Licenses: %s, DeprecationDate: %s, EOLDate: %s, PURL: %s, CPEs: %s }",
+		d.ID, d.Name, d.Version, d.URI, d.SHA256, d.SHA512, d.Checksum, d.Signature, d.Stacks, d.Licenses,
+		d.DeprecationDate, d.EOLDate, d.PURL, d.CPEs)
+}
+
 // Validate ensures that the dependency is valid.
 func (d Dependency) Validate() error {
 	if "" == d.ID {
@@ -94,8 +172,32 @@ func (d Dependency) Validate() error {
 		return fmt.Errorf("uri is required")
 	}
 
-	if "" == d.SHA256 {
-		return fmt.Errorf("sha256 is required")
+	if "" != d.SHA256 {
+		if err := validateDigest("sha256", d.SHA256); err != nil {
+			return err
+		}
+	}
+
+	if "" != d.SHA512 {
+		if err := validateDigest("sha512", d.SHA512); err != nil {
+			return err
+		}
+	}
+
+	if "" != d.Checksum {
+		if err := d.Checksum.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := d.StrongestChecksum(); !ok {
+		return fmt.Errorf("at least one strong checksum (sha256, sha512, or checksum) is required")
+	}
+
+	if d.Signature != nil {
+		if err := d.Signature.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if err := d.Stacks.Validate(); err != nil {
@@ -106,5 +208,21 @@ func (d Dependency) Validate() error {
 		return err
 	}
 
+	if err := d.validateLifecycle(); err != nil {
+		return err
+	}
+
+	if "" != d.PURL {
+		if err := validatePURL(d.PURL); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range d.CPEs {
+		if err := validateCPE(c); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -17,7 +17,9 @@
 package buildpack_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/heroku/libhkbuildpack/buildpack"
 	"github.com/heroku/libhkbuildpack/internal"
@@ -67,6 +69,144 @@ func TestDependency(t *testing.T) {
 
 				g.Expect(buildpack.NewDependency(TestDep)).To(Equal(expectedDep))
 			})
+
+			it("constructs a dependency with additional checksums", func() {
+				d, err := buildpack.NewDependency(map[string]interface{}{
+					"id":       "test-id",
+					"sha512":   "test-sha512",
+					"checksum": "sha384:test-sha384",
+				})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(d.SHA512).To(Equal("test-sha512"))
+				g.Expect(d.Checksum).To(Equal(buildpack.Checksum("sha384:test-sha384")))
+			})
+
+			it("constructs a dependency with a signature", func() {
+				d, err := buildpack.NewDependency(map[string]interface{}{
+					"id": "test-id",
+					"signature": map[string]interface{}{
+						"uri": "test-signature-uri",
+						"key": "test-key",
+					},
+				})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(d.Signature).To(Equal(&buildpack.Signature{URI: "test-signature-uri", Key: "test-key"}))
+			})
+		})
+
+		when("identifiers", func() {
+
+			var dependency buildpack.Dependency
+
+			it.Before(func() {
+				dependency = buildpack.Dependency{
+					ID:       "test-id",
+					Name:     "test-name",
+					Version:  internal.NewTestVersion(t, "1.0.0"),
+					URI:      "test-uri",
+					SHA256:   "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					Stacks:   buildpack.Stacks{"test-stack"},
+					Licenses: buildpack.Licenses{buildpack.License{Type: "test-type"}},
+				}
+			})
+
+			it("constructs a dependency with identifiers", func() {
+				d, err := buildpack.NewDependency(map[string]interface{}{
+					"id":   "test-id",
+					"purl": "pkg:generic/test-name@1.0.0",
+					"cpes": []interface{}{"cpe:2.3:a:test-vendor:test-name:1.0.0:*:*:*:*:*:*:*"},
+				})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(d.PURL).To(Equal("pkg:generic/test-name@1.0.0"))
+				g.Expect(d.CPEs).To(Equal([]string{"cpe:2.3:a:test-vendor:test-name:1.0.0:*:*:*:*:*:*:*"}))
+			})
+
+			it("validates with valid identifiers", func() {
+				dependency.PURL = "pkg:maven/org.test/test-name@1.0.0?type=jar#sub/path"
+				dependency.CPEs = []string{
+					"cpe:2.3:a:test-vendor:test-name:1.0.0:*:*:*:*:*:*:*",
+					`cpe:2.3:a:test-vendor:test\:name:-:*:*:*:*:*:*:*`,
+					"cpe:/a:test-vendor:test-name:1.0.0",
+				}
+
+				g.Expect(dependency.Validate()).To(Succeed())
+			})
+
+			it("does not validate with invalid purl", func() {
+				for _, purl := range []string{"generic/test-name", "pkg:generic", "pkg:1generic/test-name", "pkg:.generic/test-name",
+					"pkg:+generic/test-name", "pkg:-generic/test-name", "pkg:generic/@1.0.0"} {
+					dependency.PURL = purl
+					g.Expect(dependency.Validate()).To(MatchError(HavePrefix(`invalid purl "%s"`, purl)))
+				}
+			})
+
+			it("does not validate with invalid cpe", func() {
+				for _, cpe := range []string{"test-cpe", "cpe:2.3:a:test-vendor:test-name", "cpe:2.3:x:test-vendor:test-name:1.0.0:*:*:*:*:*:*:*",
+					"cpe:/", "cpe:/a", "cpe:/:test-vendor:test-name", "cpe:/a:test-vendor", "cpe:/a::test-name"} {
+					dependency.CPEs = []string{cpe}
+					g.Expect(dependency.Validate()).To(MatchError(HavePrefix(`invalid cpe "%s"`, cpe)))
+				}
+			})
+		})
+
+		when("lifecycle", func() {
+			it("constructs a dependency with lifecycle dates", func() {
+				d, err := buildpack.NewDependency(map[string]interface{}{
+					"id":               "test-id",
+					"deprecation_date": "2020-01-01",
+					"eol_date":         time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(d.DeprecationDate).To(Equal("2020-01-01"))
+				g.Expect(d.EOLDate).To(Equal("2021-01-01"))
+
+				eol, ok := d.EOL()
+				g.Expect(ok).To(BeTrue())
+				g.Expect(eol).To(Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)))
+			})
+
+			it("does not have lifecycle dates by default", func() {
+				_, ok := buildpack.Dependency{}.Deprecation()
+				g.Expect(ok).To(BeFalse())
+
+				_, ok = buildpack.Dependency{}.EOL()
+				g.Expect(ok).To(BeFalse())
+			})
+		})
+
+		when("StrongestChecksum", func() {
+			it("chooses the strongest checksum", func() {
+				c, ok := buildpack.Dependency{
+					SHA256:   "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					SHA512:   "test-sha512",
+					Checksum: "sha384:test-sha384",
+				}.StrongestChecksum()
+
+				g.Expect(ok).To(BeTrue())
+				g.Expect(c).To(Equal(buildpack.Checksum("sha512:test-sha512")))
+			})
+
+			it("ignores weak checksums", func() {
+				_, ok := buildpack.Dependency{Checksum: "sha1:test-sha1"}.StrongestChecksum()
+
+				g.Expect(ok).To(BeFalse())
+			})
+		})
+
+		when("CacheKey", func() {
+			it("prefers sha256", func() {
+				g.Expect(buildpack.Dependency{SHA256: "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273", SHA512: "test-sha512"}.CacheKey()).
+					To(Equal("6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273"))
+			})
+
+			it("falls back to the strongest checksum", func() {
+				g.Expect(buildpack.Dependency{SHA512: "test-sha512", Checksum: "sha384:test-sha384"}.CacheKey()).
+					To(Equal("test-sha512"))
+			})
 		})
 
 		when("Validate", func() {
@@ -76,7 +216,7 @@ func TestDependency(t *testing.T) {
 					Name:    "test-name",
 					Version: internal.NewTestVersion(t, "1.0.0"),
 					URI:     "test-uri",
-					SHA256:  "test-sha256",
+					SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Stacks:  buildpack.Stacks{"test-stack"},
 					Licenses: buildpack.Licenses{
 						{Type: "test-type"},
@@ -89,7 +229,7 @@ func TestDependency(t *testing.T) {
 					Name:    "test-name",
 					Version: internal.NewTestVersion(t, "1.0.0"),
 					URI:     "test-uri",
-					SHA256:  "test-sha256",
+					SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Stacks:  buildpack.Stacks{"test-stack"},
 					Licenses: buildpack.Licenses{
 						{Type: "test-type"},
@@ -102,7 +242,7 @@ func TestDependency(t *testing.T) {
 					ID:      "test-id",
 					Version: internal.NewTestVersion(t, "1.0.0"),
 					URI:     "test-uri",
-					SHA256:  "test-sha256",
+					SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Stacks:  buildpack.Stacks{"test-stack"},
 					Licenses: buildpack.Licenses{
 						{Type: "test-type"},
@@ -115,7 +255,7 @@ func TestDependency(t *testing.T) {
 					ID:     "test-id",
 					Name:   "test-name",
 					URI:    "test-uri",
-					SHA256: "test-sha256",
+					SHA256: "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Stacks: buildpack.Stacks{"test-stack"},
 					Licenses: buildpack.Licenses{
 						{Type: "test-type"},
@@ -128,7 +268,7 @@ func TestDependency(t *testing.T) {
 					ID:      "test-id",
 					Name:    "test-name",
 					Version: internal.NewTestVersion(t, "1.0.0"),
-					SHA256:  "test-sha256",
+					SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Stacks:  buildpack.Stacks{"test-stack"},
 					Licenses: buildpack.Licenses{
 						{Type: "test-type"},
@@ -149,26 +289,135 @@ func TestDependency(t *testing.T) {
 				}.Validate()).NotTo(Succeed())
 			})
 
+			it("does not validate with malformed sha256", func() {
+				for _, sha256 := range []string{"test-sha256", "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e27"} {
+					g.Expect(buildpack.Dependency{
+						ID:      "test-id",
+						Name:    "test-name",
+						Version: internal.NewTestVersion(t, "1.0.0"),
+						URI:     "test-uri",
+						SHA256:  sha256,
+						Stacks:  buildpack.Stacks{"test-stack"},
+					}.Validate()).To(MatchError(fmt.Sprintf("sha256 %q must be 64 hex characters", sha256)))
+				}
+			})
+
+			it("does not validate with malformed sha512", func() {
+				g.Expect(buildpack.Dependency{
+					ID:      "test-id",
+					Name:    "test-name",
+					Version: internal.NewTestVersion(t, "1.0.0"),
+					URI:     "test-uri",
+					SHA512:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					Stacks:  buildpack.Stacks{"test-stack"},
+				}.Validate()).To(MatchError(`sha512 "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273" must be 128 hex characters`))
+			})
+
+			it("validates with only sha512", func() {
+				g.Expect(buildpack.Dependency{
+					ID:      "test-id",
+					Name:    "test-name",
+					Version: internal.NewTestVersion(t, "1.0.0"),
+					URI:     "test-uri",
+					SHA512:  "41ee5b304e3896fd496bf0193d9f2b5cc4ba74e740bfb0e33c7b9d6e8b6a49d9983586095a3c377bd2447f1f39acb6fcd8f83c95a0d7c3ef7050f32e2c29db77",
+					Stacks:  buildpack.Stacks{"test-stack"},
+					Licenses: buildpack.Licenses{
+						{Type: "test-type"},
+					},
+				}.Validate()).To(Succeed())
+			})
+
+			it("does not validate with only a weak checksum", func() {
+				g.Expect(buildpack.Dependency{
+					ID:       "test-id",
+					Name:     "test-name",
+					Version:  internal.NewTestVersion(t, "1.0.0"),
+					URI:      "test-uri",
+					Checksum: "sha1:abcdef",
+					Stacks:   buildpack.Stacks{"test-stack"},
+					Licenses: buildpack.Licenses{
+						{Type: "test-type"},
+					},
+				}.Validate()).NotTo(Succeed())
+			})
+
+			it("does not validate with invalid checksum", func() {
+				g.Expect(buildpack.Dependency{
+					ID:       "test-id",
+					Name:     "test-name",
+					Version:  internal.NewTestVersion(t, "1.0.0"),
+					URI:      "test-uri",
+					SHA256:   "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					Checksum: "test-checksum",
+					Stacks:   buildpack.Stacks{"test-stack"},
+					Licenses: buildpack.Licenses{
+						{Type: "test-type"},
+					},
+				}.Validate()).NotTo(Succeed())
+			})
+
+			it("does not validate with invalid signature", func() {
+				g.Expect(buildpack.Dependency{
+					ID:        "test-id",
+					Name:      "test-name",
+					Version:   internal.NewTestVersion(t, "1.0.0"),
+					URI:       "test-uri",
+					SHA256:    "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					Signature: &buildpack.Signature{URI: "test-signature-uri"},
+					Stacks:    buildpack.Stacks{"test-stack"},
+					Licenses: buildpack.Licenses{
+						{Type: "test-type"},
+					},
+				}.Validate()).To(MatchError("signature key is required"))
+			})
+
 			it("does not validate with invalid stacks", func() {
 				g.Expect(buildpack.Dependency{
 					ID:      "test-id",
 					Name:    "test-name",
 					Version: internal.NewTestVersion(t, "1.0.0"),
 					URI:     "test-uri",
-					SHA256:  "test-sha256",
+					SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Licenses: buildpack.Licenses{
 						{Type: "test-type"},
 					},
 				}.Validate()).NotTo(Succeed())
 			})
 
+			it("does not validate with invalid deprecation date", func() {
+				g.Expect(buildpack.Dependency{
+					ID:              "test-id",
+					Name:            "test-name",
+					Version:         internal.NewTestVersion(t, "1.0.0"),
+					URI:             "test-uri",
+					SHA256:          "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					Stacks:          buildpack.Stacks{"test-stack"},
+					Licenses:        buildpack.Licenses{buildpack.License{Type: "test-type"}},
+					DeprecationDate: "01/01/2020",
+				}.Validate()).To(MatchError(`invalid deprecation_date "01/01/2020": must be YYYY-MM-DD`))
+			})
+
+			it("does not validate with deprecation date after eol date", func() {
+				g.Expect(buildpack.Dependency{
+					ID:              "test-id",
+					Name:            "test-name",
+					Version:         internal.NewTestVersion(t, "1.0.0"),
+					URI:             "test-uri",
+					SHA256:          "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+					Stacks:          buildpack.Stacks{"test-stack"},
+					Licenses:        buildpack.Licenses{buildpack.License{Type: "test-type"}},
+					DeprecationDate: "2021-01-02",
+					EOLDate:         "2021-01-01",
+				}.Validate()).To(MatchError("deprecation_date 2021-01-02 is after eol_date 2021-01-01"))
+			})
+
 			it("does not validate with invalid licenses", func() {
 				g.Expect(buildpack.Dependency{
 					ID:      "test-id",
 					Name:    "test-name",
 					Version: internal.NewTestVersion(t, "1.0.0"),
 					URI:     "test-uri",
-					SHA256:  "test-sha256",
+					SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
 					Stacks:  buildpack.Stacks{"test-stack"},
 				}.Validate()).NotTo(Succeed())
 			})
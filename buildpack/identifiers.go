@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	purlType = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9.+-]*$`)
+
+	// cpe23 matches a CPE 2.3 formatted string binding, e.g. cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*.
+	cpe23 = regexp.MustCompile(`^cpe:2\.3:[aho*-](:(([^:\\\s]|\\.)+)){10}$`)
+
+	// cpe22 matches a CPE 2.2 URI binding with at least a part, vendor and product, e.g. cpe:/a:vendor:product:1.0.
+	cpe22 = regexp.MustCompile(`^cpe:/[aho](:[A-Za-z0-9._~%!-]+){2}(:[A-Za-z0-9._~%!-]*){0,4}$`)
+)
+
+func validatePURL(purl string) error {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return fmt.Errorf("invalid purl %q: must start with pkg:", purl)
+	}
+
+	s := strings.TrimPrefix(purl, "pkg:")
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.Trim(s, "/")
+
+	segments := strings.Split(s, "/")
+	if len(segments) < 2 {
+		return fmt.Errorf("invalid purl %q: must contain a type and a name", purl)
+	}
+
+	if !purlType.MatchString(segments[0]) {
+		return fmt.Errorf("invalid purl %q: invalid type %q", purl, segments[0])
+	}
+
+	name := segments[len(segments)-1]
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+
+	if name == "" {
+		return fmt.Errorf("invalid purl %q: name is required", purl)
+	}
+
+	for _, segment := range segments[1:] {
+		if _, err := url.PathUnescape(segment); err != nil {
+			return fmt.Errorf("invalid purl %q: %s", purl, err)
+		}
+	}
+
+	return nil
+}
+
+func validateCPE(cpe string) error {
+	if !cpe23.MatchString(cpe) && !cpe22.MatchString(cpe) {
+		return fmt.Errorf("invalid cpe %q: must be a CPE 2.3 formatted string or CPE 2.2 URI", cpe)
+	}
+
+	return nil
+}
@@ -22,18 +22,30 @@ import (
 
 // License represents a license that a Dependency is distributed under.  At least one of Name or URI MUST be specified.
 type License struct {
-	// Type is the type of the license.  This is typically the SPDX short identifier.
+	// Type is the type of the license.  This is typically an SPDX license expression (e.g. Apache-2.0 OR MIT).
 	Type string `mapstruct:"type" toml:"type"`
 
 	// URI is the location where the license can be found.
 	URI string `mapstruct:"uri" toml:"uri"`
 }
 
-// Validate ensures that license has at least one of type or uri
+// Expression returns the type of the license parsed as an SPDX license expression.
+func (l License) Expression() (LicenseExpression, error) {
+	return ParseLicenseExpression(l.Type)
+}
+
+// Validate ensures that license has at least one of type or uri, and that the type is a syntactically valid SPDX
+// license expression.  Identifiers that are not on the SPDX license list are allowed.
 func (l License) Validate() error {
 	if "" == l.Type && "" == l.URI {
 		return fmt.Errorf("license must have at least one of type or uri")
 	}
 
+	if "" != l.Type {
+		if _, err := l.Expression(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
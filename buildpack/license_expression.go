@@ -0,0 +1,222 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//go:generate go run spdx_license_list_gen.go
+
+var (
+	licenseIDString = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	licenseRef      = regexp.MustCompile(`^(?i:DocumentRef-[A-Za-z0-9.-]+:)?(?i:LicenseRef-)[A-Za-z0-9.-]+$`)
+
+	spdxLicenses   = caseInsensitive(spdxLicenseIDs)
+	spdxExceptions = caseInsensitive(spdxExceptionIDs)
+)
+
+// LicenseExpression is a parsed SPDX license expression (e.g. "Apache-2.0 OR MIT").
+type LicenseExpression struct {
+	// Normalized is the expression with SPDX identifiers and operators in their canonical case.
+	Normalized string
+
+	// Unknown are the identifiers in the expression that are not on the bundled SPDX license list.
+	Unknown []string
+}
+
+// ParseLicenseExpression parses an SPDX license expression.  Expressions combine license identifiers with the AND, OR,
+// and WITH operators and parentheses.  License identifiers may be followed by + and may be LicenseRef-<id> or
+// DocumentRef-<id>:LicenseRef-<id> references, which are never unknown.
+func ParseLicenseExpression(expression string) (LicenseExpression, error) {
+	p := licenseParser{tokens: tokenizeLicenseExpression(expression)}
+
+	if len(p.tokens) == 0 {
+		return LicenseExpression{}, fmt.Errorf("license expression is empty")
+	}
+
+	normalized, err := p.or()
+	if err != nil {
+		return LicenseExpression{}, fmt.Errorf("invalid license expression %q: %s", expression, err)
+	}
+
+	if t, ok := p.peek(); ok {
+		return LicenseExpression{}, fmt.Errorf("invalid license expression %q: unexpected %q", expression, t)
+	}
+
+	return LicenseExpression{normalized, p.unknown}, nil
+}
+
+type licenseParser struct {
+	tokens   []string
+	position int
+	unknown  []string
+}
+
+func (p *licenseParser) or() (string, error) {
+	return p.binary("OR", p.and)
+}
+
+func (p *licenseParser) and() (string, error) {
+	return p.binary("AND", p.with)
+}
+
+func (p *licenseParser) binary(operator string, operand func() (string, error)) (string, error) {
+	left, err := operand()
+	if err != nil {
+		return "", err
+	}
+
+	for p.accept(operator) {
+		right, err := operand()
+		if err != nil {
+			return "", err
+		}
+
+		left = fmt.Sprintf("%s %s %s", left, operator, right)
+	}
+
+	return left, nil
+}
+
+func (p *licenseParser) with() (string, error) {
+	license, err := p.primary()
+	if err != nil {
+		return "", err
+	}
+
+	if !p.accept("WITH") {
+		return license, nil
+	}
+
+	t, ok := p.next()
+	if !ok {
+		return "", fmt.Errorf("expected exception after WITH")
+	}
+
+	if !licenseIDString.MatchString(t) {
+		return "", fmt.Errorf("invalid exception %q", t)
+	}
+
+	exception, ok := spdxExceptions[strings.ToLower(t)]
+	if !ok {
+		p.unknown = append(p.unknown, t)
+		exception = t
+	}
+
+	return fmt.Sprintf("%s WITH %s", license, exception), nil
+}
+
+func (p *licenseParser) primary() (string, error) {
+	t, ok := p.next()
+	if !ok {
+		return "", fmt.Errorf("unexpected end of expression")
+	}
+
+	if t == "(" {
+		e, err := p.or()
+		if err != nil {
+			return "", err
+		}
+
+		if !p.accept(")") {
+			return "", fmt.Errorf("expected )")
+		}
+
+		return fmt.Sprintf("(%s)", e), nil
+	}
+
+	if isLicenseOperator(t) || t == ")" {
+		return "", fmt.Errorf("unexpected %q", t)
+	}
+
+	if licenseRef.MatchString(t) {
+		return t, nil
+	}
+
+	id := strings.TrimSuffix(t, "+")
+	if !licenseIDString.MatchString(id) {
+		return "", fmt.Errorf("invalid license identifier %q", t)
+	}
+
+	canonical, ok := spdxLicenses[strings.ToLower(t)]
+	if ok {
+		return canonical, nil
+	}
+
+	canonical, ok = spdxLicenses[strings.ToLower(id)]
+	if !ok {
+		p.unknown = append(p.unknown, t)
+		return t, nil
+	}
+
+	return canonical + strings.TrimPrefix(t, id), nil
+}
+
+func (p *licenseParser) accept(operator string) bool {
+	t, ok := p.peek()
+	if !ok || !strings.EqualFold(t, operator) {
+		return false
+	}
+
+	p.position++
+	return true
+}
+
+func (p *licenseParser) next() (string, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.position++
+	}
+
+	return t, ok
+}
+
+func (p *licenseParser) peek() (string, bool) {
+	if p.position >= len(p.tokens) {
+		return "", false
+	}
+
+	return p.tokens[p.position], true
+}
+
+func tokenizeLicenseExpression(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+func isLicenseOperator(token string) bool {
+	for _, o := range []string{"AND", "OR", "WITH"} {
+		if strings.EqualFold(token, o) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func caseInsensitive(ids []string) map[string]string {
+	m := make(map[string]string, len(ids))
+
+	for _, id := range ids {
+		m[strings.ToLower(id)] = id
+	}
+
+	return m
+}
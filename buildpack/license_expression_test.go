@@ -0,0 +1,81 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack_test
+
+import (
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestLicenseExpression(t *testing.T) {
+	spec.Run(t, "LicenseExpression", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("parses an identifier", func() {
+			g.Expect(buildpack.ParseLicenseExpression("Apache-2.0")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "Apache-2.0"}))
+		})
+
+		it("normalizes case", func() {
+			g.Expect(buildpack.ParseLicenseExpression("apache-2.0 or mit and bsd-3-clause")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "Apache-2.0 OR MIT AND BSD-3-Clause"}))
+		})
+
+		it("recognizes identifiers from the complete SPDX license list", func() {
+			g.Expect(buildpack.ParseLicenseExpression("Unicode-3.0 AND Elastic-2.0")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "Unicode-3.0 AND Elastic-2.0"}))
+		})
+
+		it("parses WITH exceptions", func() {
+			g.Expect(buildpack.ParseLicenseExpression("GPL-2.0-only with classpath-exception-2.0")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "GPL-2.0-only WITH Classpath-exception-2.0"}))
+		})
+
+		it("parses parentheses and or-later suffixes", func() {
+			g.Expect(buildpack.ParseLicenseExpression("(MIT OR LGPL-2.1+) AND (EPL-2.0)")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "(MIT OR LGPL-2.1+) AND (EPL-2.0)"}))
+
+			g.Expect(buildpack.ParseLicenseExpression("((MIT))")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "((MIT))"}))
+		})
+
+		it("allows license references", func() {
+			g.Expect(buildpack.ParseLicenseExpression("LicenseRef-test OR DocumentRef-test:LicenseRef-test")).
+				To(Equal(buildpack.LicenseExpression{Normalized: "LicenseRef-test OR DocumentRef-test:LicenseRef-test"}))
+		})
+
+		it("reports unknown identifiers", func() {
+			g.Expect(buildpack.ParseLicenseExpression("Apache2 OR MIT WITH test-exception")).
+				To(Equal(buildpack.LicenseExpression{
+					Normalized: "Apache2 OR MIT WITH test-exception",
+					Unknown:    []string{"Apache2", "test-exception"},
+				}))
+		})
+
+		it("returns error for invalid expressions", func() {
+			for _, e := range []string{"", "Apache License 2.0", "MIT OR", "AND MIT", "(MIT", "MIT)", "MIT WITH", "MIT/X11", "MIT WITH (Classpath-exception-2.0)"} {
+				_, err := buildpack.ParseLicenseExpression(e)
+				g.Expect(err).To(HaveOccurred(), e)
+			}
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -42,6 +42,20 @@ func TestLicense(t *testing.T) {
 			g.Expect(buildpack.License{Type: "test-type", URI: "test-uri "}.Validate()).To(Succeed())
 		})
 
+		it("validates with unknown identifier", func() {
+			g.Expect(buildpack.License{Type: "Apache2"}.Validate()).To(Succeed())
+		})
+
+		it("does not validate with invalid expression", func() {
+			g.Expect(buildpack.License{Type: "Apache License 2.0"}.Validate()).NotTo(Succeed())
+		})
+
+		it("returns normalized expression", func() {
+			e, err := buildpack.License{Type: "mit or apache-2.0"}.Expression()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(e.Normalized).To(Equal("MIT OR Apache-2.0"))
+		})
+
 		it("does not validate without type and uri set", func() {
 			g.Expect(buildpack.License{}.Validate()).NotTo(Succeed())
 		})
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DateFormat is the format of the deprecation_date and eol_date of a dependency.
+const DateFormat = "2006-01-02"
+
+// Deprecation returns the date the dependency is deprecated and true if a valid date is declared.
+func (d Dependency) Deprecation() (time.Time, bool) {
+	t, err := parseDate("deprecation_date", d.DeprecationDate)
+	return t, err == nil && !t.IsZero()
+}
+
+// EOL returns the date the dependency reaches end-of-life and true if a valid date is declared.
+func (d Dependency) EOL() (time.Time, bool) {
+	t, err := parseDate("eol_date", d.EOLDate)
+	return t, err == nil && !t.IsZero()
+}
+
+func (d Dependency) validateLifecycle() error {
+	deprecation, err := parseDate("deprecation_date", d.DeprecationDate)
+	if err != nil {
+		return err
+	}
+
+	eol, err := parseDate("eol_date", d.EOLDate)
+	if err != nil {
+		return err
+	}
+
+	if !deprecation.IsZero() && !eol.IsZero() && deprecation.After(eol) {
+		return fmt.Errorf("deprecation_date %s is after eol_date %s", d.DeprecationDate, d.EOLDate)
+	}
+
+	return nil
+}
+
+func parseDate(name string, date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(DateFormat, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be YYYY-MM-DD", name, date)
+	}
+
+	return t, nil
+}
+
+// unmarshalDate converts TOML local dates and date-times into the string form of a dependency date.
+func unmarshalDate(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from != reflect.TypeOf(time.Time{}) || to.Kind() != reflect.String {
+		return data, nil
+	}
+
+	return data.(time.Time).Format(DateFormat), nil
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/buildpack/libbuildpack/stack"
+)
+
+// DefaultConstraint is the version constraint that selects the default version of a dependency.
+const DefaultConstraint = "default"
+
+// Resolver resolves the best dependency for an id, version constraint, and stack from a collection of dependencies.
+type Resolver struct {
+	// Defaults are the default versions of dependencies, keyed by id.  A default is used when no version constraint
+	// (or DefaultConstraint) is specified.
+	Defaults map[string]string
+
+	// LatestPatch indicates that a default resolves to the latest patch of the default's minor version.  By default, a
+	// default resolves to exactly the default version.
+	LatestPatch bool
+
+	// Pins are exact versions of dependencies, keyed by id.  A pin takes precedence over any version constraint.
+	Pins map[string]string
+
+	// PreReleases indicates that pre-release versions are candidates for any constraint they otherwise satisfy.  By
+	// default, pre-release versions are only candidates for constraints that themselves contain a pre-release.
+	PreReleases bool
+}
+
+// Resolution is the result of resolving a dependency.
+type Resolution struct {
+	// Dependency is the resolved dependency.
+	Dependency Dependency
+
+	// Constraint is the effective version constraint used for resolution.
+	Constraint string
+
+	// Rejections explain why each of the other dependencies was not resolved.
+	Rejections []Rejection
+}
+
+// Rejection explains why a dependency was not resolved.
+type Rejection struct {
+	// Dependency is the rejected dependency.
+	Dependency Dependency
+
+	// Reason is the reason that the dependency was rejected.
+	Reason string
+}
+
+func (r Rejection) String() string {
+	return fmt.Sprintf("(%s, %s, %s): %s", r.Dependency.ID, r.Dependency.Version.Original(), r.Dependency.Stacks, r.Reason)
+}
+
+// ResolutionError is returned when no dependency satisfies an id, version constraint, and stack.
+type ResolutionError struct {
+	// ID is the requested dependency id.
+	ID string
+
+	// Constraint is the effective version constraint.
+	Constraint string
+
+	// Stack is the requested stack.
+	Stack stack.Stack
+
+	// Rejections explain why each dependency was rejected.
+	Rejections []Rejection
+}
+
+func (e ResolutionError) Error() string {
+	var s []string
+
+	for _, r := range e.Rejections {
+		d := r.Dependency
+		s = append(s, fmt.Sprintf("(%s, %s, %s)", d.ID, d.Version.Original(), d.Stacks))
+	}
+
+	return fmt.Sprintf("no valid dependencies for %s, %s, and %s in [%s]", e.ID, e.Constraint, e.Stack, strings.Join(s, ", "))
+}
+
+// Explanation returns a line per dependency explaining why it was rejected.
+func (e ResolutionError) Explanation() []string {
+	var s []string
+
+	for _, r := range e.Rejections {
+		s = append(s, r.String())
+	}
+
+	return s
+}
+
+// Resolve returns the latest version of a dependency with the given id that satisfies the version constraint and
+// supports the stack.  If the id is pinned, only the pinned version is a candidate.  If the versionConstraint is not
+// specified ("" or DefaultConstraint) and there is a default, the default version is used.  Otherwise, an unspecified
+// ("") versionConstraint uses the latest wildcard ("*").
+func (r Resolver) Resolve(dependencies Dependencies, id string, versionConstraint string, stack stack.Stack) (Resolution, error) {
+	vc := r.constraint(id, versionConstraint)
+
+	constraint, err := semver.NewConstraint(vc)
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	var (
+		candidates Dependencies
+		rejections []Rejection
+	)
+
+	for _, d := range dependencies {
+		if reason := r.reject(d, id, vc, constraint, stack); reason != "" {
+			rejections = append(rejections, Rejection{d, reason})
+		} else {
+			candidates = append(candidates, d)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return Resolution{}, ResolutionError{id, vc, stack, rejections}
+	}
+
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		return candidates[i].Version.LessThan(candidates[j].Version.Version)
+	})
+
+	best := candidates[len(candidates)-1]
+	for _, c := range candidates[:len(candidates)-1] {
+		rejections = append(rejections, Rejection{c, fmt.Sprintf("superseded by version %s", best.Version.Original())})
+	}
+
+	return Resolution{best, vc, rejections}, nil
+}
+
+func (r Resolver) constraint(id string, versionConstraint string) string {
+	if pin, ok := r.Pins[id]; ok {
+		return fmt.Sprintf("=%s", pin)
+	}
+
+	if versionConstraint != "" && versionConstraint != DefaultConstraint {
+		return versionConstraint
+	}
+
+	d, ok := r.Defaults[id]
+	if !ok || d == "" {
+		if versionConstraint == "" {
+			return "*"
+		}
+
+		return versionConstraint
+	}
+
+	if !r.LatestPatch {
+		return d
+	}
+
+	v, err := semver.NewVersion(d)
+	if err != nil {
+		return d
+	}
+
+	return fmt.Sprintf("~%d.%d", v.Major(), v.Minor())
+}
+
+func (r Resolver) reject(dependency Dependency, id string, vc string, constraint *semver.Constraints, stack stack.Stack) string {
+	if dependency.ID != id {
+		return fmt.Sprintf("id is not %s", id)
+	}
+
+	if !r.check(dependency.Version.Version, constraint) {
+		if v := dependency.Version.Version; v.Prerelease() != "" && !r.PreReleases {
+			if release, err := v.SetPrerelease(""); err == nil && constraint.Check(&release) {
+				return fmt.Sprintf("pre-release does not satisfy %s", vc)
+			}
+		}
+
+		return fmt.Sprintf("version does not satisfy %s", vc)
+	}
+
+	if !dependency.Stacks.contains(stack) {
+		return fmt.Sprintf("stack %s is not supported", stack)
+	}
+
+	return ""
+}
+
+func (r Resolver) check(version *semver.Version, constraint *semver.Constraints) bool {
+	if constraint.Check(version) {
+		return true
+	}
+
+	if !r.PreReleases || version.Prerelease() == "" {
+		return false
+	}
+
+	release, err := version.SetPrerelease("")
+	if err != nil {
+		return false
+	}
+
+	return constraint.Check(&release)
+}
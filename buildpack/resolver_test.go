@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack_test
+
+import (
+	"testing"
+
+	"github.com/buildpack/libbuildpack/stack"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestResolver(t *testing.T) {
+	spec.Run(t, "Resolver", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		dependency := func(id string, version string, stack stack.Stack) buildpack.Dependency {
+			return buildpack.Dependency{ID: id, Name: "test-name", Version: internal.NewTestVersion(t, version), Stacks: buildpack.Stacks{stack}}
+		}
+
+		var d buildpack.Dependencies
+
+		it.Before(func() {
+			d = buildpack.Dependencies{
+				dependency("test-id", "1.2.0", "test-stack-1"),
+				dependency("test-id", "1.2.5", "test-stack-1"),
+				dependency("test-id", "1.3.0", "test-stack-1"),
+				dependency("test-id", "1.4.0-rc.1", "test-stack-1"),
+				dependency("test-id", "2.0.0", "test-stack-2"),
+				dependency("test-id-2", "3.0.0", "test-stack-1"),
+			}
+		})
+
+		it("resolves the latest version", func() {
+			r, err := buildpack.Resolver{}.Resolve(d, "test-id", "", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(r.Dependency).To(Equal(d[2]))
+			g.Expect(r.Constraint).To(Equal("*"))
+		})
+
+		it("explains rejections", func() {
+			r, err := buildpack.Resolver{}.Resolve(d, "test-id", "1.*", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(r.Rejections).To(Equal([]buildpack.Rejection{
+				{Dependency: d[3], Reason: "pre-release does not satisfy 1.*"},
+				{Dependency: d[4], Reason: "version does not satisfy 1.*"},
+				{Dependency: d[5], Reason: "id is not test-id"},
+				{Dependency: d[0], Reason: "superseded by version 1.3.0"},
+				{Dependency: d[1], Reason: "superseded by version 1.3.0"},
+			}))
+		})
+
+		it("explains stack rejections", func() {
+			_, err := buildpack.Resolver{}.Resolve(d, "test-id", "2.*", "test-stack-1")
+
+			g.Expect(err).To(BeAssignableToTypeOf(buildpack.ResolutionError{}))
+			g.Expect(err.(buildpack.ResolutionError).Explanation()).To(ContainElement(
+				"(test-id, 2.0.0, [test-stack-2]): stack test-stack-1 is not supported"))
+		})
+
+		it("includes pre-releases", func() {
+			r, err := buildpack.Resolver{PreReleases: true}.Resolve(d, "test-id", "1.*", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(r.Dependency).To(Equal(d[3]))
+		})
+
+		it("includes pre-releases named by the constraint", func() {
+			r, err := buildpack.Resolver{}.Resolve(d, "test-id", ">=1.4.0-0", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(r.Dependency).To(Equal(d[3]))
+		})
+
+		it("resolves exactly the default version", func() {
+			resolver := buildpack.Resolver{Defaults: map[string]string{"test-id": "1.2.0"}}
+
+			r, err := resolver.Resolve(d, "test-id", "", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Dependency).To(Equal(d[0]))
+			g.Expect(r.Constraint).To(Equal("1.2.0"))
+
+			r, err = resolver.Resolve(d, "test-id", buildpack.DefaultConstraint, "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Dependency).To(Equal(d[0]))
+		})
+
+		it("resolves the latest patch of the default minor", func() {
+			resolver := buildpack.Resolver{Defaults: map[string]string{"test-id": "1.2.0"}, LatestPatch: true}
+
+			r, err := resolver.Resolve(d, "test-id", "", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Dependency).To(Equal(d[1]))
+			g.Expect(r.Constraint).To(Equal("~1.2"))
+
+			r, err = resolver.Resolve(d, "test-id", buildpack.DefaultConstraint, "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Dependency).To(Equal(d[1]))
+		})
+
+		it("does not use the default for a specified constraint", func() {
+			resolver := buildpack.Resolver{Defaults: map[string]string{"test-id": "1.2.0"}}
+
+			r, err := resolver.Resolve(d, "test-id", "1.3.0", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Dependency).To(Equal(d[2]))
+		})
+
+		it("resolves pinned versions", func() {
+			resolver := buildpack.Resolver{Pins: map[string]string{"test-id": "1.2.0"}}
+
+			r, err := resolver.Resolve(d, "test-id", "1.*", "test-stack-1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Dependency).To(Equal(d[0]))
+			g.Expect(r.Constraint).To(Equal("=1.2.0"))
+		})
+
+		it("returns error if pinned version does not exist", func() {
+			resolver := buildpack.Resolver{Pins: map[string]string{"test-id": "1.2.1"}}
+
+			_, err := resolver.Resolve(d, "test-id", "1.*", "test-stack-1")
+			g.Expect(err).To(MatchError(HavePrefix("no valid dependencies for test-id, =1.2.1, and test-stack-1")))
+		})
+
+		it("returns error for default constraint without a default", func() {
+			_, err := buildpack.Resolver{}.Resolve(d, "test-id", buildpack.DefaultConstraint, "test-stack-1")
+			g.Expect(err).To(HaveOccurred())
+		})
+
+		it("returns error for invalid constraint", func() {
+			_, err := buildpack.Resolver{}.Resolve(d, "test-id", "invalid", "test-stack-1")
+			g.Expect(err).To(HaveOccurred())
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"fmt"
+)
+
+// Signature represents a detached signature of a Dependency.
+type Signature struct {
+	// URI is the location of the detached signature.
+	URI string `mapstruct:"uri" toml:"uri"`
+
+	// Key is the path, relative to the buildpack root, of the PEM encoded public key that verifies the signature.
+	Key string `mapstruct:"key" toml:"key"`
+}
+
+// Validate ensures that the signature has both a uri and a key.
+func (s Signature) Validate() error {
+	if "" == s.URI {
+		return fmt.Errorf("signature uri is required")
+	}
+
+	if "" == s.Key {
+		return fmt.Errorf("signature key is required")
+	}
+
+	return nil
+}
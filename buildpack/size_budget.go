@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SizeBudgetMetadata is the key of the size budget in buildpack metadata.
+const SizeBudgetMetadata = "size_budget"
+
+// SizeBudget is the maximum size of the layers contributed by a buildpack.  Sizes are in bytes and a zero size is
+// unlimited.
+type SizeBudget struct {
+	// Layer is the maximum size of any single layer.
+	Layer int64
+
+	// Layers are the maximum sizes of specific layers by name, overriding Layer.
+	Layers map[string]int64
+
+	// Total is the maximum size of all launch layers, those that end up in the image.
+	Total int64
+
+	// Fail indicates that exceeding the budget fails the build instead of logging a warning.
+	Fail bool
+}
+
+// LayerLimit returns the maximum size of a layer by name.
+func (s SizeBudget) LayerLimit(name string) int64 {
+	if l, ok := s.Layers[name]; ok {
+		return l
+	}
+
+	return s.Layer
+}
+
+// SizeBudget returns the size_budget buildpack metadata.  Sizes are either a number of bytes or a string with a K, M,
+// or G suffix (e.g. "250M").
+//
+//	[metadata.size_budget]
+//	layer = "250M"
+//	total = "500M"
+//	fail  = true
+//
+//	[metadata.size_budget.layers]
+//	node_modules = "400M"
+func (b Buildpack) SizeBudget() (SizeBudget, error) {
+	m, ok := b.Metadata[SizeBudgetMetadata].(map[string]interface{})
+	if !ok {
+		return SizeBudget{}, nil
+	}
+
+	var (
+		s   SizeBudget
+		err error
+	)
+
+	if s.Layer, err = size(m["layer"], "layer"); err != nil {
+		return SizeBudget{}, err
+	}
+
+	if s.Total, err = size(m["total"], "total"); err != nil {
+		return SizeBudget{}, err
+	}
+
+	if f, ok := m["fail"]; ok {
+		if s.Fail, ok = f.(bool); !ok {
+			return SizeBudget{}, fmt.Errorf("%s.fail is not a boolean", SizeBudgetMetadata)
+		}
+	}
+
+	if l, ok := m["layers"].(map[string]interface{}); ok {
+		s.Layers = make(map[string]int64, len(l))
+
+		for name := range l {
+			if s.Layers[name], err = size(l[name], "layers."+name); err != nil {
+				return SizeBudget{}, err
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func size(value interface{}, key string) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return v, nil
+	case string:
+		s, err := ParseSize(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s.%s: %s", SizeBudgetMetadata, key, err.Error())
+		}
+		return s, nil
+	default:
+		return 0, fmt.Errorf("%s.%s is not a size", SizeBudgetMetadata, key)
+	}
+}
+
+// ParseSize parses a size in bytes with an optional binary K, M, or G suffix (e.g. 512K, 1.5G, or 100MB).
+func ParseSize(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSuffix(strings.TrimSuffix(v, "B"), "I")
+
+	multiplier := float64(1)
+	switch {
+	case strings.HasSuffix(v, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(v, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(v, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		v = v[:len(v)-1]
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return int64(f * multiplier), nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buildpack_test
+
+import (
+	"testing"
+
+	bp "github.com/buildpack/libbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/logger"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestSizeBudget(t *testing.T) {
+	spec.Run(t, "SizeBudget", func(t *testing.T, when spec.G, it spec.S) {
+		g := NewWithT(t)
+
+		when("SizeBudget", func() {
+			it("returns size_budget if it exists", func() {
+				b := bp.Buildpack{
+					Metadata: bp.Metadata{
+						buildpack.SizeBudgetMetadata: map[string]interface{}{
+							"layer": "250M",
+							"total": int64(1024),
+							"fail":  true,
+							"layers": map[string]interface{}{
+								"node_modules": "1.5G",
+							},
+						},
+					},
+				}
+
+				s, err := buildpack.NewBuildpack(b, logger.New(nil)).SizeBudget()
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(s).To(Equal(buildpack.SizeBudget{
+					Layer:  250 * 1024 * 1024,
+					Layers: map[string]int64{"node_modules": 1536 * 1024 * 1024},
+					Total:  1024,
+					Fail:   true,
+				}))
+
+				g.Expect(s.LayerLimit("node_modules")).To(Equal(int64(1536 * 1024 * 1024)))
+				g.Expect(s.LayerLimit("other")).To(Equal(int64(250 * 1024 * 1024)))
+			})
+
+			it("returns empty budget if size_budget does not exist", func() {
+				g.Expect(buildpack.NewBuildpack(bp.Buildpack{}, logger.New(nil)).SizeBudget()).To(Equal(buildpack.SizeBudget{}))
+			})
+
+			it("returns an error for an invalid size", func() {
+				b := bp.Buildpack{
+					Metadata: bp.Metadata{
+						buildpack.SizeBudgetMetadata: map[string]interface{}{
+							"layers": map[string]interface{}{"node_modules": "lots"},
+						},
+					},
+				}
+
+				_, err := buildpack.NewBuildpack(b, logger.New(nil)).SizeBudget()
+				g.Expect(err).To(MatchError(`size_budget.layers.node_modules: invalid size "lots"`))
+			})
+		})
+
+		when("ParseSize", func() {
+			it("parses sizes", func() {
+				g.Expect(buildpack.ParseSize("100")).To(Equal(int64(100)))
+				g.Expect(buildpack.ParseSize("512K")).To(Equal(int64(512 * 1024)))
+				g.Expect(buildpack.ParseSize("100MB")).To(Equal(int64(100 * 1024 * 1024)))
+				g.Expect(buildpack.ParseSize("2GiB")).To(Equal(int64(2 * 1024 * 1024 * 1024)))
+				g.Expect(buildpack.ParseSize("1.5g")).To(Equal(int64(1536 * 1024 * 1024)))
+			})
+
+			it("returns an error for a negative size", func() {
+				_, err := buildpack.ParseSize("-1M")
+				g.Expect(err).To(MatchError(`invalid size "-1M"`))
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,175 @@
+//go:build ignore
+
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// spdx_license_list_gen generates spdx_license_list.go from the JSON license and exception lists published at
+// https://github.com/spdx/license-list-data.  Run it from the buildpack directory with the version of the list to
+// bundle:
+//
+//	go run spdx_license_list_gen.go -version v3.27.0
+//
+// The lists can also be read from local copies with the -licenses and -exceptions flags.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const source = "https://raw.githubusercontent.com/spdx/license-list-data/%s/json/%s"
+
+type licenseList struct {
+	Version     string `json:"licenseListVersion"`
+	ReleaseDate string `json:"releaseDate"`
+	Licenses    []struct {
+		ID string `json:"licenseId"`
+	} `json:"licenses"`
+}
+
+type exceptionList struct {
+	Version    string `json:"licenseListVersion"`
+	Exceptions []struct {
+		ID string `json:"licenseExceptionId"`
+	} `json:"exceptions"`
+}
+
+func main() {
+	version := flag.String("version", "main", "version of the SPDX license list to download")
+	licensesPath := flag.String("licenses", "", "path to licenses.json instead of downloading it")
+	exceptionsPath := flag.String("exceptions", "", "path to exceptions.json instead of downloading it")
+	output := flag.String("output", "spdx_license_list.go", "file to generate")
+	flag.Parse()
+
+	var licenses licenseList
+	if err := read(*licensesPath, *version, "licenses.json", &licenses); err != nil {
+		log.Fatal(err)
+	}
+
+	var exceptions exceptionList
+	if err := read(*exceptionsPath, *version, "exceptions.json", &exceptions); err != nil {
+		log.Fatal(err)
+	}
+
+	if licenses.Version != exceptions.Version {
+		log.Fatalf("license list version %s does not match exception list version %s", licenses.Version, exceptions.Version)
+	}
+
+	var licenseIDs []string
+	for _, l := range licenses.Licenses {
+		licenseIDs = append(licenseIDs, l.ID)
+	}
+
+	var exceptionIDs []string
+	for _, e := range exceptions.Exceptions {
+		exceptionIDs = append(exceptionIDs, e.ID)
+	}
+
+	header, err := ioutil.ReadFile(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b := &bytes.Buffer{}
+	b.WriteString(license(string(header)))
+	fmt.Fprintf(b, "// Code generated by spdx_license_list_gen.go; DO NOT EDIT.\n\n")
+	fmt.Fprintf(b, "package buildpack\n\n")
+	fmt.Fprintf(b, "// SPDXLicenseListVersion is the version of the bundled SPDX License List, released %s.\n", releaseDate(licenses.ReleaseDate))
+	fmt.Fprintf(b, "const SPDXLicenseListVersion = %q\n\n", licenses.Version)
+	fmt.Fprintf(b, "// spdxLicenseIDs are license identifiers, including deprecated identifiers, from the SPDX License List\n")
+	fmt.Fprintf(b, "// (https://spdx.org/licenses/).\n")
+	writeIDs(b, "spdxLicenseIDs", licenseIDs)
+	fmt.Fprintf(b, "\n// spdxExceptionIDs are license exception identifiers from the SPDX License Exceptions List\n")
+	fmt.Fprintf(b, "// (https://spdx.org/licenses/exceptions-index.html).\n")
+	writeIDs(b, "spdxExceptionIDs", exceptionIDs)
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*output, out, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// read decodes a list from path, or downloads it from the SPDX license-list-data repository if path is empty.
+func read(path string, version string, name string, v interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	if path != "" {
+		b, err = ioutil.ReadFile(path)
+	} else {
+		b, err = download(fmt.Sprintf(source, version, name))
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func download(uri string) ([]byte, error) {
+	resp, err := http.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download %s: %d", uri, resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// license returns the license comment at the top of the existing file.
+func license(existing string) string {
+	if i := strings.Index(existing, "*/\n"); strings.HasPrefix(existing, "/*") && i >= 0 {
+		return existing[:i+3] + "\n"
+	}
+
+	return ""
+}
+
+func releaseDate(date string) string {
+	if i := strings.Index(date, "T"); i >= 0 {
+		return date[:i]
+	}
+
+	return date
+}
+
+func writeIDs(b *bytes.Buffer, name string, ids []string) {
+	sort.Strings(ids)
+
+	fmt.Fprintf(b, "var %s = []string{\n", name)
+	for _, id := range ids {
+		fmt.Fprintf(b, "%q,\n", id)
+	}
+	fmt.Fprintf(b, "}\n")
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package execd supports writing exec.d helpers: executables contributed to a layer that are run by the launcher
+// before each process starts and that emit environment variables as TOML.  Unlike profile.d scripts, they do not
+// require a shell in the run image.
+//
+//	func main() {
+//	    execd.Run(func() (map[string]string, error) {
+//	        return map[string]string{"JAVA_TOOL_OPTIONS": memoryOptions()}, nil
+//	    })
+//	}
+package execd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// Error_Execute is the exit code when a helper fails.
+	Error_Execute = 101
+
+	// Error_Output is the exit code when a helper's environment cannot be written.
+	Error_Output = 102
+
+	// OutputFD is the file descriptor the launcher reads a helper's environment from.
+	OutputFD = 3
+)
+
+// Helper is a function, run at launch, that returns the environment variables to set for the process.
+type Helper func() (map[string]string, error)
+
+// Run runs a helper, writes the environment it returns to file descriptor 3, and exits.  Failures are written to
+// stderr and exit with a non-zero status code.
+func Run(helper Helper) {
+	out := os.NewFile(OutputFD, fmt.Sprintf("/dev/fd/%d", OutputFD))
+
+	code, err := Execute(helper, out)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s\n", err)
+	}
+
+	os.Exit(code)
+}
+
+// Execute runs a helper and writes the environment it returns as TOML to out, returning the status code the helper
+// should exit with.
+func Execute(helper Helper, out io.Writer) (int, error) {
+	env, err := helper()
+	if err != nil {
+		return Error_Execute, fmt.Errorf("unable to execute helper: %s", err.Error())
+	}
+
+	if len(env) == 0 {
+		return 0, nil
+	}
+
+	if err := toml.NewEncoder(out).Encode(env); err != nil {
+		return Error_Output, fmt.Errorf("unable to write environment: %s", err.Error())
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/execd"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestExecD(t *testing.T) {
+	spec.Run(t, "ExecD", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var out *bytes.Buffer
+
+		it.Before(func() {
+			out = &bytes.Buffer{}
+		})
+
+		it("writes environment as TOML", func() {
+			code, err := execd.Execute(func() (map[string]string, error) {
+				return map[string]string{
+					"JAVA_TOOL_OPTIONS": "-Xmx512M",
+					"TEST_QUOTED":       `test "value"`,
+				}, nil
+			}, out)
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(code).To(Equal(0))
+			g.Expect(out.String()).To(Equal(`JAVA_TOOL_OPTIONS = "-Xmx512M"
+TEST_QUOTED = "test \"value\""
+`))
+		})
+
+		it("writes nothing for empty environment", func() {
+			code, err := execd.Execute(func() (map[string]string, error) {
+				return nil, nil
+			}, out)
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(code).To(Equal(0))
+			g.Expect(out.String()).To(BeEmpty())
+		})
+
+		it("returns error code when helper fails", func() {
+			code, err := execd.Execute(func() (map[string]string, error) {
+				return nil, fmt.Errorf("test-error")
+			}, out)
+
+			g.Expect(err).To(MatchError("unable to execute helper: test-error"))
+			g.Expect(code).To(Equal(execd.Error_Execute))
+		})
+	}, spec.Report(report.Terminal{}))
+}
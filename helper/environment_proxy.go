@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// EnvironmentProxy returns a proxy function configured by the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment
+// variables (or their lowercase versions) at the time of the call.  Unlike http.ProxyFromEnvironment, the environment
+// is not cached for the life of the process, so platform environment variables set during initialization are honored.
+// NO_PROXY is a comma separated list of hosts, domain suffixes (with or without a leading .), IP addresses, CIDR
+// ranges, or *.
+func EnvironmentProxy() func(*http.Request) (*url.URL, error) {
+	httpProxy := getenv("HTTP_PROXY")
+	httpsProxy := getenv("HTTPS_PROXY")
+	noProxy := strings.Split(getenv("NO_PROXY"), ",")
+
+	return func(req *http.Request) (*url.URL, error) {
+		var proxy string
+		switch req.URL.Scheme {
+		case "http":
+			proxy = httpProxy
+		case "https":
+			proxy = httpsProxy
+		}
+
+		if proxy == "" || bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+
+		u, err := url.Parse(proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			// proxies are commonly specified without a scheme
+			return url.Parse("http://" + proxy)
+		}
+
+		return u, nil
+	}
+}
+
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if entry == "*" {
+			return true
+		}
+
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getenv(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return os.Getenv(strings.ToLower(key))
+}
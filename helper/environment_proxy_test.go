@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/helper"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestEnvironmentProxy(t *testing.T) {
+	spec.Run(t, "EnvironmentProxy", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		proxy := func(uri string) *url.URL {
+			t.Helper()
+
+			req, err := http.NewRequest("GET", uri, nil)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			u, err := helper.EnvironmentProxy()(req)
+			g.Expect(err).NotTo(HaveOccurred())
+			return u
+		}
+
+		var restore []func()
+
+		it.Before(func() {
+			for _, k := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+				restore = append(restore, test.ReplaceEnv(t, k, ""))
+			}
+		})
+
+		it.After(func() {
+			for _, r := range restore {
+				r()
+			}
+			restore = nil
+		})
+
+		it("uses proxy by scheme", func() {
+			defer test.ReplaceEnv(t, "HTTP_PROXY", "http://http-proxy:3128")()
+			defer test.ReplaceEnv(t, "HTTPS_PROXY", "https-proxy:3128")()
+
+			g.Expect(proxy("http://test-host/test-path").String()).To(Equal("http://http-proxy:3128"))
+			g.Expect(proxy("https://test-host/test-path").String()).To(Equal("http://https-proxy:3128"))
+		})
+
+		it("reads environment on each call", func() {
+			g.Expect(proxy("https://test-host/test-path")).To(BeNil())
+
+			defer test.ReplaceEnv(t, "https_proxy", "http://test-proxy")()
+			g.Expect(proxy("https://test-host/test-path").String()).To(Equal("http://test-proxy"))
+		})
+
+		it("honors NO_PROXY", func() {
+			defer test.ReplaceEnv(t, "HTTPS_PROXY", "http://test-proxy")()
+			defer test.ReplaceEnv(t, "NO_PROXY", "exact-host, .example.com,internal.net:443,10.0.0.0/8")()
+
+			g.Expect(proxy("https://exact-host/test-path")).To(BeNil())
+			g.Expect(proxy("https://sub.exact-host/test-path")).To(BeNil())
+			g.Expect(proxy("https://www.example.com/test-path")).To(BeNil())
+			g.Expect(proxy("https://deep.internal.net/test-path")).To(BeNil())
+			g.Expect(proxy("https://10.1.2.3/test-path")).To(BeNil())
+			g.Expect(proxy("https://localhost/test-path")).To(BeNil())
+
+			g.Expect(proxy("https://notexample.com/test-path")).NotTo(BeNil())
+			g.Expect(proxy("https://11.1.2.3/test-path")).NotTo(BeNil())
+		})
+
+		it("bypasses all hosts with *", func() {
+			defer test.ReplaceEnv(t, "HTTPS_PROXY", "http://test-proxy")()
+			defer test.ReplaceEnv(t, "NO_PROXY", "*")()
+
+			g.Expect(proxy("https://test-host/test-path")).To(BeNil())
+		})
+	}, spec.Report(report.Terminal{}))
+}
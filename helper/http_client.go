@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// CABundleEnv is the environment variable containing the path to a PEM encoded CA bundle that is trusted in
+	// addition to the system roots.
+	CABundleEnv = "BP_CA_BUNDLE"
+
+	// ConnectTimeoutEnv is the environment variable containing the connect timeout (e.g. 30s) of HTTP clients.
+	ConnectTimeoutEnv = "BP_HTTP_CONNECT_TIMEOUT"
+
+	// ReadTimeoutEnv is the environment variable containing the read timeout (e.g. 5m) of HTTP clients.
+	ReadTimeoutEnv = "BP_HTTP_READ_TIMEOUT"
+)
+
+// HTTPClientOptions configures the HTTP clients created by NewHTTPClient.
+type HTTPClientOptions struct {
+	// ConnectTimeout is the maximum time allowed to establish a connection.  A zero value indicates no timeout.
+	ConnectTimeout time.Duration
+
+	// ReadTimeout is the maximum time allowed between reads from a connection.  A zero value indicates no timeout.
+	ReadTimeout time.Duration
+
+	// CABundle is the path to a PEM encoded CA bundle that is trusted in addition to the system roots.
+	CABundle string
+
+	// Proxy returns the proxy to use for a request.  If nil, no proxy is used.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// Transport, if set, is used by the client instead of a transport configured from these options.
+	Transport http.RoundTripper
+}
+
+// DefaultHTTPClientOptions creates a new instance of HTTPClientOptions, extracting values from the BP_CA_BUNDLE,
+// BP_HTTP_CONNECT_TIMEOUT, BP_HTTP_READ_TIMEOUT, and standard proxy environment variables.
+func DefaultHTTPClientOptions() (HTTPClientOptions, error) {
+	o := HTTPClientOptions{
+		ConnectTimeout: 30 * time.Second,
+		ReadTimeout:    5 * time.Minute,
+		CABundle:       os.Getenv(CABundleEnv),
+		Proxy:          EnvironmentProxy(),
+	}
+
+	if s, ok := os.LookupEnv(ConnectTimeoutEnv); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return HTTPClientOptions{}, fmt.Errorf("invalid %s: %s", ConnectTimeoutEnv, err.Error())
+		}
+		o.ConnectTimeout = d
+	}
+
+	if s, ok := os.LookupEnv(ReadTimeoutEnv); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return HTTPClientOptions{}, fmt.Errorf("invalid %s: %s", ReadTimeoutEnv, err.Error())
+		}
+		o.ReadTimeout = d
+	}
+
+	return o, nil
+}
+
+// NewHTTPClient creates a new http.Client configured with options.  Unless a transport is injected, the client also
+// supports file:// URIs.
+func NewHTTPClient(options HTTPClientOptions) (*http.Client, error) {
+	if options.Transport != nil {
+		return &http.Client{Transport: options.Transport}, nil
+	}
+
+	dialer := &net.Dialer{Timeout: options.ConnectTimeout, KeepAlive: 30 * time.Second}
+
+	t := &http.Transport{
+		Proxy: options.Proxy,
+		DialContext: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			c, err := dialer.DialContext(ctx, network, address)
+			if err != nil || options.ReadTimeout <= 0 {
+				return c, err
+			}
+
+			return &timeoutConn{c, options.ReadTimeout}, nil
+		},
+		TLSHandshakeTimeout:   options.ConnectTimeout,
+		ResponseHeaderTimeout: options.ReadTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	if options.CABundle != "" {
+		pool, err := certPool(options.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+
+	return &http.Client{Transport: t}, nil
+}
+
+func certPool(bundle string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	b, err := ioutil.ReadFile(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA bundle %s: %s", bundle, err.Error())
+	}
+
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("CA bundle %s does not contain any PEM encoded certificates", bundle)
+	}
+
+	return pool, nil
+}
+
+// timeoutConn is a net.Conn that fails a read if no data is received within a timeout.
+type timeoutConn struct {
+	net.Conn
+
+	timeout time.Duration
+}
+
+func (c *timeoutConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+
+	return c.Conn.Read(b)
+}
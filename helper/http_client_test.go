@@ -0,0 +1,149 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/heroku/libhkbuildpack/helper"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestHTTPClient(t *testing.T) {
+	spec.Run(t, "HTTPClient", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		when("DefaultHTTPClientOptions", func() {
+
+			it("extracts timeouts and CA bundle from environment", func() {
+				defer test.ReplaceEnv(t, helper.CABundleEnv, "/test/ca.pem")()
+				defer test.ReplaceEnv(t, helper.ConnectTimeoutEnv, "10s")()
+				defer test.ReplaceEnv(t, helper.ReadTimeoutEnv, "1m")()
+
+				o, err := helper.DefaultHTTPClientOptions()
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(o.CABundle).To(Equal("/test/ca.pem"))
+				g.Expect(o.ConnectTimeout).To(Equal(10 * time.Second))
+				g.Expect(o.ReadTimeout).To(Equal(time.Minute))
+				g.Expect(o.Proxy).NotTo(BeNil())
+			})
+
+			it("returns error for invalid timeout", func() {
+				defer test.ReplaceEnv(t, helper.ReadTimeoutEnv, "forever")()
+
+				_, err := helper.DefaultHTTPClientOptions()
+				g.Expect(err).To(MatchError(ContainSubstring("invalid BP_HTTP_READ_TIMEOUT")))
+			})
+		})
+
+		when("NewHTTPClient", func() {
+
+			it("trusts CA bundle", func() {
+				server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte("test-payload"))
+				}))
+				defer server.Close()
+
+				c, err := helper.NewHTTPClient(helper.HTTPClientOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+				_, err = c.Get(server.URL)
+				g.Expect(err).To(HaveOccurred())
+
+				bundle := filepath.Join(test.ScratchDir(t, "http-client"), "ca.pem")
+				test.WriteFile(t, bundle, "%s", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+				c, err = helper.NewHTTPClient(helper.HTTPClientOptions{CABundle: bundle})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				resp, err := c.Get(server.URL)
+				g.Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				g.Expect(ioutil.ReadAll(resp.Body)).To(Equal([]byte("test-payload")))
+			})
+
+			it("returns error for CA bundle without certificates", func() {
+				bundle := filepath.Join(test.ScratchDir(t, "http-client"), "ca.pem")
+				test.WriteFile(t, bundle, "not a certificate")
+
+				_, err := helper.NewHTTPClient(helper.HTTPClientOptions{CABundle: bundle})
+				g.Expect(err).To(MatchError(ContainSubstring("does not contain any PEM encoded certificates")))
+			})
+
+			it("times out stalled reads", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte("test"))
+					w.(http.Flusher).Flush()
+					time.Sleep(500 * time.Millisecond)
+				}))
+				defer server.Close()
+
+				c, err := helper.NewHTTPClient(helper.HTTPClientOptions{ReadTimeout: 50 * time.Millisecond})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				resp, err := c.Get(server.URL)
+				g.Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+
+				_, err = ioutil.ReadAll(resp.Body)
+				g.Expect(err).To(HaveOccurred())
+			})
+
+			it("supports file URIs", func() {
+				file := filepath.Join(test.ScratchDir(t, "http-client"), "test-file")
+				test.WriteFile(t, file, "test-payload")
+
+				c, err := helper.NewHTTPClient(helper.HTTPClientOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				resp, err := c.Get("file://" + file)
+				g.Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				g.Expect(ioutil.ReadAll(resp.Body)).To(Equal([]byte("test-payload")))
+			})
+
+			it("uses injected transport", func() {
+				transport := roundTripper(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusTeapot, Body: ioutil.NopCloser(nil), Request: r}, nil
+				})
+
+				c, err := helper.NewHTTPClient(helper.HTTPClientOptions{Transport: transport})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				resp, err := c.Get("http://test-host/test-path")
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(resp.StatusCode).To(Equal(http.StatusTeapot))
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r(req)
+}
@@ -16,18 +16,30 @@
 
 package internal
 
-// Set represents the mathematical type set.
+import (
+	"fmt"
+	"sync"
+)
+
+// Set represents the mathematical type set.  A Set is safe for concurrent use.
 type Set struct {
 	contents map[interface{}]struct{}
+	mutex    *sync.RWMutex
 }
 
 // Add adds an element to the set.
 func (s Set) Add(v interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.contents[v] = struct{}{}
 }
 
 // Contains returns whether the set contains an item.
 func (s Set) Contains(v interface{}) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	_, ok := s.contents[v]
 	return ok
 }
@@ -49,15 +61,22 @@ func (s Set) Difference(other Set) Set {
 // Iterator is a type that range-able.
 type Iterator <-chan interface{}
 
-// Iterator returns the values to be ranged over.
+// Iterator returns the values to be ranged over.  The values are those in the set at the time of the call.
 func (s Set) Iterator() Iterator {
+	s.mutex.RLock()
+	values := make([]interface{}, 0, len(s.contents))
+	for k := range s.contents {
+		values = append(values, k)
+	}
+	s.mutex.RUnlock()
+
 	ch := make(chan interface{})
 
 	go func() {
 		defer close(ch)
 
-		for k, _ := range s.contents {
-			ch <- k
+		for _, v := range values {
+			ch <- v
 		}
 	}()
 
@@ -66,10 +85,23 @@ func (s Set) Iterator() Iterator {
 
 // Size returns the number of elements in the set.
 func (s Set) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	return len(s.contents)
 }
 
+// String makes Set satisfy the Stringer interface.
+func (s Set) String() string {
+	var values []interface{}
+	for v := range s.Iterator() {
+		values = append(values, v)
+	}
+
+	return fmt.Sprintf("%v", values)
+}
+
 // NewSet creates an initialized and empty Set.
 func NewSet() Set {
-	return Set{make(map[interface{}]struct{})}
+	return Set{make(map[interface{}]struct{}), &sync.RWMutex{}}
 }
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"sync"
+
+	"github.com/buildpack/libbuildpack/buildplan"
+	"github.com/heroku/libhkbuildpack/buildpack"
+)
+
+// buildPlans guards a shared build plan so that layers can contribute to it concurrently.
+type buildPlans struct {
+	plans buildplan.BuildPlan
+	mutex *sync.Mutex
+}
+
+func (b buildPlans) add(id string, dependency buildplan.Dependency) {
+	if b.mutex != nil {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+	}
+
+	b.plans[id] = dependency
+}
+
+// dependencyBuildPlan creates the build plan entry for a contributed dependency.  The package URL and CPEs are only
+// included when the dependency declares them.
+func dependencyBuildPlan(dependency buildpack.Dependency) buildplan.Dependency {
+	metadata := buildplan.Metadata{
+		"name":     dependency.Name,
+		"uri":      dependency.URI,
+		"sha256":   dependency.SHA256,
+		"stacks":   dependency.Stacks,
+		"licenses": dependency.Licenses,
+	}
+
+	if dependency.PURL != "" {
+		metadata["purl"] = dependency.PURL
+	}
+
+	if len(dependency.CPEs) > 0 {
+		metadata["cpes"] = dependency.CPEs
+	}
+
+	return buildplan.Dependency{Version: dependency.Version.Original(), Metadata: metadata}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/logger"
+)
+
+// LayerSize is the size of a contributed layer.
+type LayerSize struct {
+	// Name is the name of the layer.
+	Name string
+
+	// Size is the total size of the files in the layer in bytes.
+	Size int64
+
+	// Files is the number of files in the layer.
+	Files int
+
+	// Launch indicates whether the layer is a launch layer and therefore part of the image.
+	Launch bool
+}
+
+// BuildSummary records the sizes of layers contributed during a build and enforces a size budget on them.
+type BuildSummary struct {
+	// Budget is the size budget of contributed layers.
+	Budget buildpack.SizeBudget
+
+	logger *logger.Log
+	mutex  sync.Mutex
+	sizes  map[string]LayerSize
+}
+
+// Record measures a layer contributed with flags and records its size, warning or failing if the layer exceeds its
+// budget.
+func (s *BuildSummary) Record(layer Layer, flags ...Flag) error {
+	size, err := measure(layer)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range flags {
+		if f == Launch {
+			size.Launch = true
+		}
+	}
+
+	s.mutex.Lock()
+	s.sizes[size.Name] = size
+	s.mutex.Unlock()
+
+	s.logger.Debug("Layer %s: %d bytes in %d files", size.Name, size.Size, size.Files)
+
+	if limit := s.Budget.LayerLimit(size.Name); limit > 0 && size.Size > limit {
+		return s.exceeded("Layer %s is %s, exceeding its budget of %s",
+			size.Name, formatBytes(float64(size.Size)), formatBytes(float64(limit)))
+	}
+
+	return nil
+}
+
+// Layers returns the recorded layer sizes ordered by name.
+func (s *BuildSummary) Layers() []LayerSize {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var sizes []LayerSize
+	for _, l := range s.sizes {
+		sizes = append(sizes, l)
+	}
+
+	sort.Slice(sizes, func(i int, j int) bool {
+		return sizes[i].Name < sizes[j].Name
+	})
+
+	return sizes
+}
+
+// Total returns the total size of all recorded launch layers in bytes.
+func (s *BuildSummary) Total() int64 {
+	var total int64
+	for _, l := range s.Layers() {
+		if l.Launch {
+			total += l.Size
+		}
+	}
+
+	return total
+}
+
+// NonLaunch returns the total size of all recorded layers that are not launch layers in bytes.  These layers are not
+// part of the image and do not count towards the total budget.
+func (s *BuildSummary) NonLaunch() int64 {
+	var total int64
+	for _, l := range s.Layers() {
+		if !l.Launch {
+			total += l.Size
+		}
+	}
+
+	return total
+}
+
+// Report logs the recorded layer sizes, warning or failing if the total size of launch layers exceeds its budget.  Reporting a nil
+// summary does nothing.
+func (s *BuildSummary) Report() error {
+	if s == nil {
+		return nil
+	}
+
+	sizes := s.Layers()
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	s.logger.FirstLine("Layer sizes:")
+	for _, l := range sizes {
+		s.logger.SubsequentLine("%s: %s (%d files)", l.Name, formatBytes(float64(l.Size)), l.Files)
+	}
+
+	total := s.Total()
+	s.logger.SubsequentLine("Total: %s", formatBytes(float64(total)))
+
+	if nonLaunch := s.NonLaunch(); nonLaunch > 0 {
+		s.logger.SubsequentLine("Not in image: %s", formatBytes(float64(nonLaunch)))
+	}
+
+	if s.Budget.Total > 0 && total > s.Budget.Total {
+		return s.exceeded("Launch layers are %s, exceeding the total budget of %s",
+			formatBytes(float64(total)), formatBytes(float64(s.Budget.Total)))
+	}
+
+	return nil
+}
+
+func (s *BuildSummary) exceeded(format string, args ...interface{}) error {
+	if s.Budget.Fail {
+		return fmt.Errorf(format, args...)
+	}
+
+	s.logger.Warning(format, args...)
+	return nil
+}
+
+func measure(layer Layer) (LayerSize, error) {
+	size := LayerSize{Name: filepath.Base(layer.Root)}
+
+	err := filepath.Walk(layer.Root, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == layer.Root {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			size.Size += info.Size()
+			size.Files++
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+// NewBuildSummary creates a new instance of BuildSummary with a size budget.
+func NewBuildSummary(budget buildpack.SizeBudget, logger *logger.Log) *BuildSummary {
+	return &BuildSummary{Budget: budget, logger: logger, sizes: make(map[string]LayerSize)}
+}
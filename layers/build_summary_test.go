@@ -0,0 +1,127 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestBuildSummary(t *testing.T) {
+	spec.Run(t, "BuildSummary", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			info *bytes.Buffer
+			l    layers.Layers
+		)
+
+		contribute := func(name string, size int, flags ...layers.Flag) error {
+			t.Helper()
+
+			return l.Layer(name).Contribute(metadata{name, size}, func(layer layers.Layer) error {
+				test.WriteFile(t, filepath.Join(layer.Root, "alpha"), "%s", strings.Repeat("a", size))
+				test.WriteFile(t, filepath.Join(layer.Root, "sub", "bravo"), "b")
+				return nil
+			}, flags...)
+		}
+
+		it.Before(func() {
+			info = &bytes.Buffer{}
+			l = layers.NewLayers(bp.Layers{Root: test.ScratchDir(t, "build-summary")}, bp.Layers{}, buildpack.Buildpack{}, logger.NewFromWriters(nil, info))
+		})
+
+		it("records layer sizes after contribution", func() {
+			g.Expect(contribute("test-layer-2", 99, layers.Launch)).To(Succeed())
+			g.Expect(contribute("test-layer-1", 9, layers.Launch)).To(Succeed())
+			g.Expect(contribute("test-layer-3", 999, layers.Cache)).To(Succeed())
+
+			g.Expect(l.Summary.Layers()).To(Equal([]layers.LayerSize{
+				{Name: "test-layer-1", Size: 10, Files: 2, Launch: true},
+				{Name: "test-layer-2", Size: 100, Files: 2, Launch: true},
+				{Name: "test-layer-3", Size: 1000, Files: 2},
+			}))
+			g.Expect(l.Summary.Total()).To(Equal(int64(110)))
+			g.Expect(l.Summary.NonLaunch()).To(Equal(int64(1000)))
+		})
+
+		it("records reused layer sizes", func() {
+			g.Expect(contribute("test-layer", 9)).To(Succeed())
+
+			l = layers.NewLayers(bp.Layers{Root: l.Root}, bp.Layers{}, buildpack.Buildpack{}, logger.NewFromWriters(nil, info))
+			g.Expect(contribute("test-layer", 9)).To(Succeed())
+
+			g.Expect(l.Summary.Layers()).To(Equal([]layers.LayerSize{{Name: "test-layer", Size: 10, Files: 2}}))
+		})
+
+		it("warns when a layer exceeds its budget", func() {
+			l.Summary.Budget = buildpack.SizeBudget{Layer: 50}
+
+			g.Expect(contribute("test-layer", 99)).To(Succeed())
+			g.Expect(info.String()).To(ContainSubstring("Layer test-layer is 100 B, exceeding its budget of 50 B"))
+		})
+
+		it("fails when a layer exceeds its budget", func() {
+			l.Summary.Budget = buildpack.SizeBudget{Layers: map[string]int64{"test-layer": 50}, Fail: true}
+
+			g.Expect(contribute("test-layer", 99)).To(MatchError("Layer test-layer is 100 B, exceeding its budget of 50 B"))
+			g.Expect(contribute("other-layer", 99)).To(Succeed())
+		})
+
+		it("reports sizes", func() {
+			g.Expect(contribute("test-layer", 99, layers.Launch)).To(Succeed())
+			g.Expect(contribute("cache-layer", 9, layers.Cache)).To(Succeed())
+
+			g.Expect(l.Summary.Report()).To(Succeed())
+			g.Expect(info.String()).To(ContainSubstring("Layer sizes:"))
+			g.Expect(info.String()).To(ContainSubstring("test-layer: 100 B (2 files)"))
+			g.Expect(info.String()).To(ContainSubstring("cache-layer: 10 B (2 files)"))
+			g.Expect(info.String()).To(ContainSubstring("Total: 100 B"))
+			g.Expect(info.String()).To(ContainSubstring("Not in image: 10 B"))
+		})
+
+		it("fails when launch layers exceed total budget", func() {
+			l.Summary.Budget = buildpack.SizeBudget{Total: 150, Fail: true}
+
+			g.Expect(contribute("test-layer-1", 99, layers.Launch)).To(Succeed())
+			g.Expect(contribute("test-layer-2", 99, layers.Build, layers.Launch)).To(Succeed())
+
+			g.Expect(l.Summary.Report()).To(MatchError("Launch layers are 200 B, exceeding the total budget of 150 B"))
+		})
+
+		it("does not count cache-only layers towards total budget", func() {
+			l.Summary.Budget = buildpack.SizeBudget{Total: 150, Fail: true}
+
+			g.Expect(contribute("test-layer", 99, layers.Launch)).To(Succeed())
+			g.Expect(contribute("cache-layer", 999, layers.Cache)).To(Succeed())
+
+			g.Expect(l.Summary.Report()).To(Succeed())
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Contribution is a single layer contribution (e.g. a call to DependencyLayer.Contribute) that can run concurrently
+// with contributions to other layers.
+type Contribution func() error
+
+// ContributionErrors are the errors returned by contributions run by ContributeParallel, in the order of the
+// contributions.
+type ContributionErrors []error
+
+func (c ContributionErrors) Error() string {
+	if len(c) == 1 {
+		return c[0].Error()
+	}
+
+	var s []string
+	for _, e := range c {
+		s = append(s, e.Error())
+	}
+
+	return fmt.Sprintf("%d contributions failed: %s", len(c), strings.Join(s, "; "))
+}
+
+// ContributeParallel runs contributions concurrently, at most concurrency at a time, and waits for all of them to
+// complete.  Each contribution must contribute to a different layer.  If any contributions fail, their errors are
+// returned as ContributionErrors.
+func ContributeParallel(concurrency int, contributions ...Contribution) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(contributions))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, c := range contributions {
+		wg.Add(1)
+
+		go func(i int, c Contribution) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			errs[i] = c()
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	var failed ContributionErrors
+	for _, e := range errs {
+		if e != nil {
+			failed = append(failed, e)
+		}
+	}
+
+	if len(failed) > 0 {
+		return failed
+	}
+
+	return nil
+}
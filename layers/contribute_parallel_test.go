@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	layersBp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestContributeParallel(t *testing.T) {
+	spec.Run(t, "ContributeParallel", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("contributes dependency layers concurrently", func() {
+			root := test.ScratchDir(t, "contribute-parallel")
+			ls := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{}, buildpack.Buildpack{}, &logger.Log{})
+
+			var contributions []layers.Contribution
+			for i := 0; i < 8; i++ {
+				d := buildpack.Dependency{
+					ID:      fmt.Sprintf("test-id-%d", i),
+					Version: internal.NewTestVersion(t, "1.0"),
+					SHA256:  fmt.Sprintf("test-sha256-%d", i),
+					URI:     "https://test.com/test-path",
+				}
+
+				test.WriteFile(t, filepath.Join(root, fmt.Sprintf("%s.toml", d.SHA256)), `[metadata]
+ID = "%s"
+Version = "%s"
+SHA256 = "%s"
+URI = "%s"`, d.ID, d.Version.Original(), d.SHA256, d.URI)
+
+				layer := ls.DependencyLayer(d)
+				contributions = append(contributions, func() error {
+					return layer.Contribute(func(artifact string, layer layers.DependencyLayer) error {
+						return nil
+					})
+				})
+			}
+
+			g.Expect(layers.ContributeParallel(4, contributions...)).To(Succeed())
+			g.Expect(ls.DependencyBuildPlans).To(HaveLen(8))
+			g.Expect(ls.TouchedLayers.Cleanup()).To(Succeed())
+			g.Expect(filepath.Join(root, "test-id-0.toml")).To(BeARegularFile())
+		})
+
+		it("limits concurrency", func() {
+			var current, max int32
+
+			var contributions []layers.Contribution
+			for i := 0; i < 6; i++ {
+				contributions = append(contributions, func() error {
+					c := atomic.AddInt32(&current, 1)
+					for {
+						m := atomic.LoadInt32(&max)
+						if c <= m || atomic.CompareAndSwapInt32(&max, m, c) {
+							break
+						}
+					}
+
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&current, -1)
+					return nil
+				})
+			}
+
+			g.Expect(layers.ContributeParallel(2, contributions...)).To(Succeed())
+			g.Expect(max).To(BeNumerically("<=", 2))
+		})
+
+		it("aggregates errors", func() {
+			err := layers.ContributeParallel(2,
+				func() error { return fmt.Errorf("test-error-1") },
+				func() error { return nil },
+				func() error { return fmt.Errorf("test-error-2") },
+			)
+
+			g.Expect(err).To(Equal(layers.ContributionErrors{fmt.Errorf("test-error-1"), fmt.Errorf("test-error-2")}))
+			g.Expect(err).To(MatchError("2 contributions failed: test-error-1; test-error-2"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
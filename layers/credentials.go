@@ -0,0 +1,210 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// NetrcEnv is the environment variable used to locate a netrc file.  If not set, $HOME/.netrc is used.
+	NetrcEnv = "NETRC"
+
+	// TokenEnvPrefix is the prefix of environment variables that contain a bearer token for a host.  The host is
+	// upper-cased and all non-alphanumeric characters are replaced with underscores (e.g. BP_DEPENDENCY_TOKEN_EXAMPLE_COM).
+	TokenEnvPrefix = "BP_DEPENDENCY_TOKEN_"
+
+	// UsernameEnvPrefix is the prefix of environment variables that contain a basic authentication username for a host.
+	UsernameEnvPrefix = "BP_DEPENDENCY_USERNAME_"
+
+	// PasswordEnvPrefix is the prefix of environment variables that contain a basic authentication password for a host.
+	PasswordEnvPrefix = "BP_DEPENDENCY_PASSWORD_"
+)
+
+// Credentials supplies authentication for dependency downloads.  Credentials are applied to requests only and are
+// never written to layer metadata or logs.
+type Credentials struct {
+	machines []netrcMachine
+	env      func(string) (string, bool)
+}
+
+// DefaultCredentials creates a new instance of Credentials using the current process environment and the netrc file
+// located by the NETRC environment variable or $HOME/.netrc.
+func DefaultCredentials() (Credentials, error) {
+	c := Credentials{env: os.LookupEnv}
+
+	path, ok := os.LookupEnv(NetrcEnv)
+	if !ok {
+		u, err := user.Current()
+		if err != nil {
+			return c, nil
+		}
+		path = filepath.Join(u.HomeDir, ".netrc")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return c, err
+	}
+
+	c.machines, err = parseNetrc(string(b))
+	if err != nil {
+		return Credentials{env: os.LookupEnv}, fmt.Errorf("unable to parse %s: %s", path, err.Error())
+	}
+
+	return c, nil
+}
+
+// NewCredentials creates a new instance of Credentials from the contents of a netrc file and a function used to look
+// up environment variables.
+func NewCredentials(netrc string, env func(string) (string, bool)) (Credentials, error) {
+	machines, err := parseNetrc(netrc)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return Credentials{machines, env}, nil
+}
+
+// Apply adds authentication for the request's host to the request.  Bearer tokens take precedence over basic
+// authentication from the environment, which takes precedence over netrc entries.
+func (c Credentials) Apply(req *http.Request) {
+	host := req.URL.Hostname()
+	if host == "" {
+		return
+	}
+
+	if c.env != nil {
+		key := hostKey(host)
+
+		if token, ok := c.env(TokenEnvPrefix + key); ok && token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			return
+		}
+
+		if username, ok := c.env(UsernameEnvPrefix + key); ok && username != "" {
+			password, _ := c.env(PasswordEnvPrefix + key)
+			req.SetBasicAuth(username, password)
+			return
+		}
+	}
+
+	var def *netrcMachine
+	for i, m := range c.machines {
+		if m.name == host {
+			req.SetBasicAuth(m.login, m.password)
+			return
+		}
+
+		if m.name == "" && def == nil {
+			def = &c.machines[i]
+		}
+	}
+
+	if def != nil {
+		req.SetBasicAuth(def.login, def.password)
+	}
+}
+
+var nonAlphanumeric = regexp.MustCompile("[^A-Z0-9]")
+
+func hostKey(host string) string {
+	return nonAlphanumeric.ReplaceAllString(strings.ToUpper(host), "_")
+}
+
+type netrcMachine struct {
+	name     string
+	login    string
+	password string
+}
+
+// parseNetrc parses the machine, default, login, and password tokens of a netrc file.  A default entry is represented
+// by an empty name.
+func parseNetrc(content string) ([]netrcMachine, error) {
+	var machines []netrcMachine
+	var current *netrcMachine
+
+	fields := netrcFields(content)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("machine is missing a name")
+			}
+			i++
+			machines = append(machines, netrcMachine{name: fields[i]})
+			current = &machines[len(machines)-1]
+		case "default":
+			machines = append(machines, netrcMachine{})
+			current = &machines[len(machines)-1]
+		case "login", "password", "account":
+			if current == nil {
+				return nil, fmt.Errorf("%s must follow machine or default", fields[i])
+			}
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("%s is missing a value", fields[i])
+			}
+			i++
+			if fields[i-1] == "login" {
+				current.login = fields[i]
+			} else if fields[i-1] == "password" {
+				current.password = fields[i]
+			}
+		default:
+			return nil, fmt.Errorf("unexpected token in position %d", i+1)
+		}
+	}
+
+	return machines, nil
+}
+
+// netrcFields splits a netrc file into tokens, skipping comments and macro definitions.
+func netrcFields(content string) []string {
+	var fields []string
+
+	macro := false
+	for _, line := range strings.Split(content, "\n") {
+		if macro {
+			macro = strings.TrimSpace(line) != ""
+			continue
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		for _, f := range strings.Fields(line) {
+			if f == "macdef" {
+				macro = true
+				break
+			}
+
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
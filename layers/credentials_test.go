@@ -0,0 +1,139 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestCredentials(t *testing.T) {
+	spec.Run(t, "Credentials", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			env map[string]string
+			req *http.Request
+		)
+
+		lookup := func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		}
+
+		it.Before(func() {
+			env = make(map[string]string)
+
+			var err error
+			req, err = http.NewRequest("GET", "https://artifacts.example.com/test-path", nil)
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("applies a bearer token from the environment", func() {
+			env["BP_DEPENDENCY_TOKEN_ARTIFACTS_EXAMPLE_COM"] = "test-token"
+
+			c, err := layers.NewCredentials("machine artifacts.example.com login test-login password test-password", lookup)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c.Apply(req)
+			g.Expect(req.Header.Get("Authorization")).To(Equal("Bearer test-token"))
+		})
+
+		it("applies basic authentication from the environment", func() {
+			env["BP_DEPENDENCY_USERNAME_ARTIFACTS_EXAMPLE_COM"] = "test-username"
+			env["BP_DEPENDENCY_PASSWORD_ARTIFACTS_EXAMPLE_COM"] = "test-password"
+
+			c, err := layers.NewCredentials("", lookup)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c.Apply(req)
+			username, password, ok := req.BasicAuth()
+			g.Expect(ok).To(BeTrue())
+			g.Expect(username).To(Equal("test-username"))
+			g.Expect(password).To(Equal("test-password"))
+		})
+
+		it("applies basic authentication from a matching netrc machine", func() {
+			c, err := layers.NewCredentials(`# comment
+default login default-login password default-password
+machine artifacts.example.com
+  login test-login
+  password test-password
+`, lookup)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c.Apply(req)
+			username, password, ok := req.BasicAuth()
+			g.Expect(ok).To(BeTrue())
+			g.Expect(username).To(Equal("test-login"))
+			g.Expect(password).To(Equal("test-password"))
+		})
+
+		it("applies basic authentication from the netrc default", func() {
+			c, err := layers.NewCredentials(`machine other.example.com login other-login password other-password
+macdef init
+  machine artifacts.example.com login macro-login
+
+default login default-login password default-password
+`, lookup)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c.Apply(req)
+			username, _, ok := req.BasicAuth()
+			g.Expect(ok).To(BeTrue())
+			g.Expect(username).To(Equal("default-login"))
+		})
+
+		it("does not apply authentication without credentials", func() {
+			c, err := layers.NewCredentials("machine other.example.com login other-login", lookup)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c.Apply(req)
+			g.Expect(req.Header.Get("Authorization")).To(BeEmpty())
+		})
+
+		it("does not include credentials in parse errors", func() {
+			_, err := layers.NewCredentials("login test-login password test-password", lookup)
+			g.Expect(err).To(MatchError("login must follow machine or default"))
+
+			_, err = layers.NewCredentials("machine artifacts.example.com test-secret", lookup)
+			g.Expect(err).To(MatchError("unexpected token in position 3"))
+		})
+
+		it("reads the netrc file from NETRC", func() {
+			root := test.ScratchDir(t, "credentials")
+			test.WriteFile(t, filepath.Join(root, ".netrc"), "machine artifacts.example.com login test-login password test-password")
+			defer test.ReplaceEnv(t, layers.NetrcEnv, filepath.Join(root, ".netrc"))()
+
+			c, err := layers.DefaultCredentials()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			c.Apply(req)
+			username, _, ok := req.BasicAuth()
+			g.Expect(ok).To(BeTrue())
+			g.Expect(username).To(Equal("test-login"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
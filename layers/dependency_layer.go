@@ -19,10 +19,11 @@ package layers
 import (
 	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/buildpack/libbuildpack/buildplan"
 	"github.com/heroku/libhkbuildpack/buildpack"
 	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/sbom"
 )
 
 // DependencyLayer is an extension to Layer that is unique to a dependency.
@@ -32,8 +33,9 @@ type DependencyLayer struct {
 	// Dependency is the dependency provided by this layer.
 	Dependency buildpack.Dependency
 
-	dependencyBuildPlans buildplan.BuildPlan
+	dependencyBuildPlans buildPlans
 	downloadLayer        DownloadLayer
+	eolWindow            time.Duration
 	logger               *logger.Log
 }
 
@@ -47,9 +49,21 @@ type DependencyLayerContributor func(artifact string, layer DependencyLayer) err
 
 // Contribute facilitates custom contribution of an artifact to a layer.  If the artifact has already been contributed,
 // the contribution is validated and the contributor is not called.  If the contribution is out of date, the layer is
-// completely removed before contribution occurs.
+// completely removed before contribution occurs.  A warning is logged if the dependency is deprecated or within the
+// end-of-life window.  A bill-of-materials for the dependency is written alongside the layer metadata.  In plan mode,
+// the contribution and any required download are recorded instead.
 func (l DependencyLayer) Contribute(contributor DependencyLayerContributor, flags ...Flag) error {
 	l.downloadLayer.Touch()
+	warnLifecycle(l.logger, l.Dependency, l.eolWindow, time.Now())
+
+	if l.planning() {
+		if err := l.planContribution(flags...); err != nil {
+			return err
+		}
+
+		l.contributeToBuildPlan()
+		return nil
+	}
 
 	if err := l.Layer.Contribute(l.Dependency, func(layer Layer) error {
 		if err := os.RemoveAll(l.Root); err != nil {
@@ -67,20 +81,26 @@ func (l DependencyLayer) Contribute(contributor DependencyLayerContributor, flag
 	}
 
 	l.contributeToBuildPlan()
-	return nil
+	return l.WriteSBOM(sbom.NewComponent(l.Dependency))
 }
 
 func (l *DependencyLayer) contributeToBuildPlan() {
 	l.logger.Debug("Contributing %s to bill-of-materials", l.Dependency.ID)
 
-	l.dependencyBuildPlans[l.Dependency.ID] = buildplan.Dependency{
-		Version: l.Dependency.Version.Original(),
-		Metadata: buildplan.Metadata{
-			"name":     l.Dependency.Name,
-			"uri":      l.Dependency.URI,
-			"sha256":   l.Dependency.SHA256,
-			"stacks":   l.Dependency.Stacks,
-			"licenses": l.Dependency.Licenses,
-		},
+	l.dependencyBuildPlans.add(l.Dependency.ID, dependencyBuildPlan(l.Dependency))
+}
+
+// planContribution records the contribution of the layer and the download it would require in plan mode.
+func (l DependencyLayer) planContribution(flags ...Flag) error {
+	matches, err := l.MetadataMatches(l.Dependency)
+	if err != nil {
+		return err
+	}
+
+	if err := l.contribute(l.Dependency, l.Dependency, matches, nil, flags...); err != nil || matches {
+		return err
 	}
+
+	_, err = l.downloadLayer.Artifact()
+	return err
 }
@@ -17,9 +17,12 @@
 package layers_test
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/buildpack/libbuildpack/buildplan"
 	layersBp "github.com/buildpack/libbuildpack/layers"
@@ -34,7 +37,7 @@ import (
 )
 
 func TestDependencyLayer(t *testing.T) {
-	spec.Run(t, "DependencyLayer", func(t *testing.T, _ spec.G, it spec.S) {
+	spec.Run(t, "DependencyLayer", func(t *testing.T, when spec.G, it spec.S) {
 
 		g := NewGomegaWithT(t)
 
@@ -124,6 +127,32 @@ URI = "%s"`, dependency.ID, dependency.Version.Original(), dependency.SHA256, de
 			}))
 		})
 
+		it("contributes identifiers to build plan", func() {
+			dependency.PURL = "pkg:generic/test-name@1.0"
+			dependency.CPEs = []string{"cpe:2.3:a:test-vendor:test-name:1.0:*:*:*:*:*:*:*"}
+			layer = ls.DependencyLayer(dependency)
+			g.Expect(layer.WriteMetadata(dependency)).To(Succeed())
+
+			g.Expect(layer.Contribute(func(artifact string, layer layers.DependencyLayer) error {
+				return nil
+			})).To(Succeed())
+
+			g.Expect(ls.DependencyBuildPlans[dependency.ID].Metadata).To(HaveKeyWithValue("purl", "pkg:generic/test-name@1.0"))
+			g.Expect(ls.DependencyBuildPlans[dependency.ID].Metadata).To(HaveKeyWithValue("cpes", dependency.CPEs))
+		})
+
+		it("writes bills-of-materials", func() {
+			g.Expect(layer.WriteMetadata(dependency)).To(Succeed())
+
+			g.Expect(layer.Contribute(func(artifact string, layer layers.DependencyLayer) error {
+				return nil
+			})).To(Succeed())
+
+			g.Expect(filepath.Join(root, "test-id.sbom.cdx.json")).To(BeARegularFile())
+			g.Expect(filepath.Join(root, "test-id.sbom.spdx.json")).To(BeARegularFile())
+			g.Expect(ioutil.ReadFile(filepath.Join(root, "test-id.sbom.cdx.json"))).To(ContainSubstring(`"version": "1.0"`))
+		})
+
 		it("cleans layer when contributing dependency layer", func() {
 			test.WriteFile(t, filepath.Join(root, fmt.Sprintf("%s.toml", dependency.SHA256)), `[metadata]
 ID = "%s"
@@ -138,5 +167,57 @@ URI = "%s"`, dependency.ID, dependency.Version.Original(), dependency.SHA256, de
 
 			g.Expect(filepath.Join(layer.Root, "test-file")).NotTo(BeAnExistingFile())
 		})
+
+		when("lifecycle", func() {
+
+			var info *bytes.Buffer
+
+			contribute := func(deprecation time.Time, eol time.Time) {
+				if !deprecation.IsZero() {
+					dependency.DeprecationDate = deprecation.Format(buildpack.DateFormat)
+				}
+				if !eol.IsZero() {
+					dependency.EOLDate = eol.Format(buildpack.DateFormat)
+				}
+
+				ls = layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{}, buildpack.Buildpack{}, logger.NewFromWriters(nil, info))
+				ls.EOLWindow = 30 * 24 * time.Hour
+				layer = ls.DependencyLayer(dependency)
+				g.Expect(layer.WriteMetadata(dependency)).To(Succeed())
+
+				g.Expect(layer.Contribute(func(artifact string, layer layers.DependencyLayer) error {
+					return nil
+				})).To(Succeed())
+			}
+
+			it.Before(func() {
+				info = &bytes.Buffer{}
+			})
+
+			it("warns when past end-of-life", func() {
+				contribute(time.Time{}, time.Now().AddDate(0, 0, -1))
+
+				g.Expect(info.String()).To(ContainSubstring("reached end-of-life on %s", dependency.EOLDate))
+			})
+
+			it("warns when within end-of-life window", func() {
+				contribute(time.Time{}, time.Now().AddDate(0, 0, 10))
+
+				g.Expect(info.String()).To(ContainSubstring("reaches end-of-life on %s", dependency.EOLDate))
+			})
+
+			it("warns when deprecated", func() {
+				contribute(time.Now().AddDate(0, 0, -1), time.Now().AddDate(1, 0, 0))
+
+				g.Expect(info.String()).To(ContainSubstring("was deprecated on %s", dependency.DeprecationDate))
+				g.Expect(info.String()).NotTo(ContainSubstring("end-of-life"))
+			})
+
+			it("does not warn outside end-of-life window", func() {
+				contribute(time.Time{}, time.Now().AddDate(0, 0, 60))
+
+				g.Expect(info.String()).NotTo(ContainSubstring("end-of-life"))
+			})
+		})
 	}, spec.Report(report.Terminal{}))
 }
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/helper"
+)
+
+// DownloadCacheEnv is the environment variable containing the root of a content addressed download cache shared
+// across buildpacks and builds on the same host.
+const DownloadCacheEnv = "BP_DOWNLOAD_CACHE"
+
+// DownloadCache is a content addressed store of downloaded artifacts, keyed by digest.  Artifacts are hard linked
+// between the cache and layers, falling back to a copy when a link cannot be created (e.g. across filesystems).
+type DownloadCache struct {
+	// Root is the root directory of the cache.  An empty root disables the cache.
+	Root string
+}
+
+// DefaultDownloadCache creates a new instance of DownloadCache rooted at the BP_DOWNLOAD_CACHE environment variable.
+func DefaultDownloadCache() DownloadCache {
+	return DownloadCache{os.Getenv(DownloadCacheEnv)}
+}
+
+// Enabled returns whether the cache has been configured.
+func (c DownloadCache) Enabled() bool {
+	return c.Root != ""
+}
+
+// Get links the artifact with a checksum into destination, returning false if the cache does not contain the
+// artifact.
+func (c DownloadCache) Get(checksum buildpack.Checksum, destination string) (bool, error) {
+	if exists, err := c.Has(checksum); err != nil || !exists {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return false, err
+	}
+
+	if err := os.RemoveAll(destination); err != nil {
+		return false, err
+	}
+
+	if err := link(c.entry(checksum), destination); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Has returns whether the cache contains the artifact with a checksum.
+func (c DownloadCache) Has(checksum buildpack.Checksum) (bool, error) {
+	return helper.FileExists(c.entry(checksum))
+}
+
+// Lock acquires an exclusive lock on the artifact with a checksum, blocking until any other process or goroutine
+// holding the lock releases it.  The returned function releases the lock.
+func (c DownloadCache) Lock(checksum buildpack.Checksum) (func(), error) {
+	lock := c.entry(checksum) + ".lock"
+
+	if err := os.MkdirAll(filepath.Dir(lock), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(lock, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to lock %s: %s", lock, err.Error())
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// Put adds the artifact at source to the cache under a checksum.  The artifact is added atomically so that concurrent
+// readers never observe a partial entry.
+func (c DownloadCache) Put(checksum buildpack.Checksum, source string) error {
+	entry := c.entry(checksum)
+
+	if err := os.MkdirAll(filepath.Dir(entry), 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(entry), fmt.Sprintf(".%s.*.tmp", checksum.Hex()))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	f.Close()
+
+	if err := os.Remove(tmp); err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if err := link(source, tmp); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, entry)
+}
+
+// Remove removes the artifact with a checksum from the cache.
+func (c DownloadCache) Remove(checksum buildpack.Checksum) error {
+	if err := os.Remove(c.entry(checksum)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (c DownloadCache) entry(checksum buildpack.Checksum) string {
+	return filepath.Join(c.Root, checksum.Algorithm(), checksum.Hex())
+}
+
+func link(source string, destination string) error {
+	if err := os.Link(source, destination); err == nil {
+		return nil
+	}
+
+	return helper.CopyFile(source, destination)
+}
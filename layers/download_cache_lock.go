@@ -0,0 +1,55 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	fileLocks      = make(map[string]*sync.Mutex)
+	fileLocksMutex sync.Mutex
+)
+
+// lockFile acquires an exclusive lock on a file, blocking until it is available.  flock(2) is not available on this
+// platform, so the lock only excludes other goroutines in this process.
+func lockFile(f *os.File) error {
+	fileLock(f.Name()).Lock()
+	return nil
+}
+
+// unlockFile releases a lock acquired by lockFile.
+func unlockFile(f *os.File) error {
+	fileLock(f.Name()).Unlock()
+	return nil
+}
+
+func fileLock(path string) *sync.Mutex {
+	fileLocksMutex.Lock()
+	defer fileLocksMutex.Unlock()
+
+	m, ok := fileLocks[path]
+	if !ok {
+		m = &sync.Mutex{}
+		fileLocks[path] = m
+	}
+
+	return m
+}
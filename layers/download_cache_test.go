@@ -0,0 +1,111 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestDownloadCache(t *testing.T) {
+	spec.Run(t, "DownloadCache", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			root     string
+			cache    layers.DownloadCache
+			checksum = buildpack.NewChecksum("sha256", "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273")
+		)
+
+		it.Before(func() {
+			root = test.ScratchDir(t, "download-cache")
+			cache = layers.DownloadCache{Root: filepath.Join(root, "cache")}
+		})
+
+		it("is disabled without a root", func() {
+			g.Expect(layers.DownloadCache{}.Enabled()).To(BeFalse())
+			g.Expect(cache.Enabled()).To(BeTrue())
+		})
+
+		it("extracts root from BP_DOWNLOAD_CACHE", func() {
+			defer test.ReplaceEnv(t, "BP_DOWNLOAD_CACHE", "/test/cache")()
+
+			g.Expect(layers.DefaultDownloadCache()).To(Equal(layers.DownloadCache{Root: "/test/cache"}))
+		})
+
+		it("returns false for missing artifact", func() {
+			g.Expect(cache.Has(checksum)).To(BeFalse())
+			g.Expect(cache.Get(checksum, filepath.Join(root, "destination"))).To(BeFalse())
+		})
+
+		it("links artifact by digest", func() {
+			source := filepath.Join(root, "source")
+			test.WriteFile(t, source, "test-payload")
+
+			g.Expect(cache.Put(checksum, source)).To(Succeed())
+			g.Expect(filepath.Join(root, "cache", "sha256", checksum.Hex())).To(test.HaveContent("test-payload"))
+			g.Expect(cache.Has(checksum)).To(BeTrue())
+
+			destination := filepath.Join(root, "layer", "destination")
+			g.Expect(cache.Get(checksum, destination)).To(BeTrue())
+			g.Expect(destination).To(test.HaveContent("test-payload"))
+
+			s, err := os.Stat(source)
+			g.Expect(err).NotTo(HaveOccurred())
+			d, err := os.Stat(destination)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(os.SameFile(s, d)).To(BeTrue())
+		})
+
+		it("removes artifact", func() {
+			source := filepath.Join(root, "source")
+			test.WriteFile(t, source, "test-payload")
+			g.Expect(cache.Put(checksum, source)).To(Succeed())
+
+			g.Expect(cache.Remove(checksum)).To(Succeed())
+			g.Expect(cache.Get(checksum, filepath.Join(root, "destination"))).To(BeFalse())
+		})
+
+		it("blocks concurrent locks", func() {
+			unlock, err := cache.Lock(checksum)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			locked := make(chan struct{})
+			go func() {
+				u, err := cache.Lock(checksum)
+				if err == nil {
+					u()
+				}
+				close(locked)
+			}()
+
+			g.Consistently(locked, 100*time.Millisecond).ShouldNot(BeClosed())
+			unlock()
+			g.Eventually(locked).Should(BeClosed())
+		})
+	}, spec.Report(report.Terminal{}))
+}
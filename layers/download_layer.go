@@ -17,13 +17,15 @@
 package layers
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/heroku/libhkbuildpack/buildpack"
 	"github.com/heroku/libhkbuildpack/helper"
@@ -34,18 +36,34 @@ import (
 type DownloadLayer struct {
 	Layer
 
-	cacheLayer Layer
-	dependency buildpack.Dependency
-	info       buildpack.Info
-	logger     *logger.Log
+	cacheLayer    Layer
+	client        *http.Client
+	credentials   Credentials
+	dependency    buildpack.Dependency
+	downloadCache DownloadCache
+	info          buildpack.Info
+	keyRoot       string
+	label         string
+	logger        *logger.Log
+	mirrors       Mirrors
+	offline       bool
+	progress      Progress
+	retry         Retry
+	started       func(dependency buildpack.Dependency)
 }
 
 // Artifact returns the path to an artifact cached in the layer.  If the artifact has already been downloaded, the cache
 // will be validated and used directly.  If the artifact is out of date, the layer is left untouched and the contributor
-// is responsible for cleaning the layer if necessary.
+// is responsible for cleaning the layer if necessary.  If a DownloadCache is configured, it is consulted before
+// downloading and populated afterwards.  In offline mode, an OfflineError is returned instead of downloading an
+// artifact over the network.
 func (l DownloadLayer) Artifact() (string, error) {
 	l.Touch()
 
+	if l.started != nil {
+		l.started(l.dependency)
+	}
+
 	matches, err := l.cacheLayer.MetadataMatches(l.dependency)
 	if err != nil {
 		return "", err
@@ -53,7 +71,11 @@ func (l DownloadLayer) Artifact() (string, error) {
 
 	artifact := filepath.Join(l.cacheLayer.Root, filepath.Base(l.dependency.URI))
 	if matches {
-		l.logger.SubsequentLine("%s cached download from buildpack", "Reusing")
+		if l.planned(PlanReuse, "%s cached download from buildpack", l.Logger.PrettyIdentity(l.dependency)) {
+			return artifact, nil
+		}
+
+		l.log("%s cached download from buildpack", "Reusing")
 		return artifact, nil
 	}
 
@@ -64,24 +86,56 @@ func (l DownloadLayer) Artifact() (string, error) {
 
 	artifact = filepath.Join(l.Root, filepath.Base(l.dependency.URI))
 	if matches {
-		l.logger.SubsequentLine("%s cached download from previous build", "Reusing")
+		if l.planned(PlanReuse, "%s cached download from previous build", l.Logger.PrettyIdentity(l.dependency)) {
+			return artifact, nil
+		}
+
+		l.log("%s cached download from previous build", "Reusing")
 		return artifact, nil
 	}
 
-	if err := os.RemoveAll(l.Root); err != nil {
+	checksum, _ := l.dependency.StrongestChecksum()
+	if l.planning() {
+		return artifact, l.plan()
+	}
+
+	if l.downloadCache.Enabled() {
+		unlock, err := l.downloadCache.Lock(checksum)
+		if err != nil {
+			return "", err
+		}
+		defer unlock()
+
+		if ok, err := l.fromDownloadCache(checksum, artifact); err != nil {
+			return "", err
+		} else if ok {
+			return artifact, nil
+		}
+	}
+
+	if l.offline && !l.isLocal() {
+		return "", OfflineError{l.dependency}
+	}
+
+	partial := l.partial(artifact)
+	if err := l.clean(partial); err != nil {
 		return "", err
 	}
 
-	l.logger.SubsequentLine("%s from %s", "Downloading", l.dependency.URI)
-	if err := l.download(artifact); err != nil {
+	if err := l.fetch(partial); err != nil {
 		return "", err
 	}
 
-	l.logger.SubsequentLine("Verifying checksum")
-	if err := l.verify(artifact); err != nil {
+	if err := os.Rename(partial, artifact); err != nil {
 		return "", err
 	}
 
+	if l.downloadCache.Enabled() {
+		if err := l.downloadCache.Put(checksum, artifact); err != nil {
+			l.logger.Warning("Unable to add %s to download cache: %s", l.dependency.ID, err.Error())
+		}
+	}
+
 	if err := l.WriteMetadata(l.dependency, Cache); err != nil {
 		return "", err
 	}
@@ -89,32 +143,255 @@ func (l DownloadLayer) Artifact() (string, error) {
 	return artifact, nil
 }
 
-func (l DownloadLayer) download(file string) error {
-	req, err := http.NewRequest("GET", l.dependency.URI, nil)
+// clean removes everything in the layer except for keep, typically a partial download that can be resumed.
+func (l DownloadLayer) clean(keep string) error {
+	files, err := ioutil.ReadDir(l.Root)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if p := filepath.Join(l.Root, f.Name()); p != keep {
+			if err := os.RemoveAll(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// plan records how the artifact would be provided in plan mode.
+func (l DownloadLayer) plan() error {
+	checksum, _ := l.dependency.StrongestChecksum()
+
+	if l.downloadCache.Enabled() {
+		if exists, err := l.downloadCache.Has(checksum); err != nil {
+			return err
+		} else if exists {
+			l.planned(PlanReuse, "%s from download cache", l.Logger.PrettyIdentity(l.dependency))
+			return nil
+		}
+	}
+
+	if l.offline && !l.isLocal() {
+		return OfflineError{l.dependency}
+	}
+
+	uri := l.dependency.URI
+	if mirror, ok := l.mirrors.Rewrite(uri); ok {
+		uri = mirror
+	}
+
+	l.planned(PlanDownload, "%s from %s", l.Logger.PrettyIdentity(l.dependency), uri)
+	return nil
+}
+
+// fromDownloadCache links the artifact from the download cache into the layer, returning false if the cache does not
+// contain a valid artifact.  Invalid cache entries are removed.
+func (l DownloadLayer) fromDownloadCache(checksum buildpack.Checksum, artifact string) (bool, error) {
+	if ok, err := l.downloadCache.Get(checksum, artifact); err != nil || !ok {
+		return false, err
+	}
+
+	if err := l.verify(artifact); err != nil {
+		l.logger.Warning("Removing invalid download cache entry: %s", err.Error())
+
+		if err := os.Remove(artifact); err != nil {
+			return false, err
+		}
+
+		return false, l.downloadCache.Remove(checksum)
+	}
+
+	if err := l.clean(artifact); err != nil {
+		return false, err
+	}
+
+	l.log("%s cached download from download cache", "Reusing")
+	return true, l.WriteMetadata(l.dependency, Cache)
+}
+
+// fetch downloads and verifies the dependency into file.  If a mirror is configured for the dependency it is tried
+// first, falling back to the original URI if the mirror fails.
+func (l DownloadLayer) fetch(file string) error {
+	if mirror, ok := l.mirrors.Rewrite(l.dependency.URI); ok {
+		l.log("%s from %s (mirror of %s)", "Downloading", mirror, l.dependency.URI)
+
+		err := l.fetchFrom(mirror, file)
+		if err == nil {
+			return nil
+		}
+
+		l.log("Mirror download failed: %s. Falling back to upstream", err.Error())
+	}
+
+	l.log("%s from %s", "Downloading", l.dependency.URI)
+	return l.fetchFrom(l.dependency.URI, file)
+}
+
+func (l DownloadLayer) fetchFrom(uri string, file string) error {
+	if err := l.download(uri, file); err != nil {
+		return err
+	}
+
+	l.log("Verifying checksum")
+	if err := l.verify(file); err != nil {
+		l.removePartial(file)
+		return err
+	}
+
+	if l.dependency.Signature != nil {
+		l.log("Verifying signature")
+		if err := l.verifySignature(file); err != nil {
+			l.removePartial(file)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// download downloads uri into file, retrying transient failures and resuming from any content already written to
+// file.
+func (l DownloadLayer) download(uri string, file string) error {
+	attempts := l.retry.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := l.downloadAttempt(uri, file)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= attempts || !isRetryable(err) {
+			return err
+		}
+
+		d := l.retry.delay(attempt)
+		l.log("Download failed: %s. Retrying in %s", err.Error(), d)
+		time.Sleep(d)
+	}
+}
+
+func (l DownloadLayer) downloadAttempt(uri string, file string) error {
+	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("User-Agent", fmt.Sprintf("%s/%s", l.info.ID, l.info.Version))
-	t := &http.Transport{Proxy: http.ProxyFromEnvironment}
-	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	l.credentials.Apply(req)
+
+	var offset int64
+	if fi, err := os.Stat(file); err == nil && fi.Size() > 0 {
+		offset = fi.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		l.logger.Debug("Resuming download of %s from byte %d", uri, offset)
+	}
+
+	client := l.client
+	if client == nil {
+		if client, err = helper.NewHTTPClient(helper.HTTPClientOptions{Proxy: helper.EnvironmentProxy()}); err != nil {
+			return err
+		}
+	}
 
-	client := http.Client{Transport: t}
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return retryableError{err}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return fmt.Errorf("could not download: %bd", resp.StatusCode)
+	flags := os.O_WRONLY | os.O_CREATE
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+		return retryableError{fmt.Errorf("could not resume download: %d", resp.StatusCode)}
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retryableError{fmt.Errorf("could not download: %d", resp.StatusCode)}
+	default:
+		return fmt.Errorf("could not download: %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var transferred int64
+	if flags&os.O_APPEND != 0 {
+		transferred = offset
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = transferred + resp.ContentLength
+	}
+
+	reader, stop := l.progress.reader(resp.Body, transferred, total, l.log)
+	_, err = io.Copy(f, reader)
+	stop()
+
+	if err != nil {
+		return retryableError{err}
+	}
+
+	return nil
+}
+
+// isLocal returns whether the dependency is served from the local filesystem rather than the network.
+func (l DownloadLayer) isLocal() bool {
+	u, err := url.Parse(l.dependency.URI)
+	return err == nil && u.Scheme == "file"
+}
+
+// log writes a subsequent line, prefixed with the dependency identity when downloads are interleaved.
+func (l DownloadLayer) log(format string, args ...interface{}) {
+	if l.label != "" {
+		format = "%s: " + format
+		args = append([]interface{}{l.label}, args...)
 	}
 
-	return helper.WriteFileFromReader(file, 0644, resp.Body)
+	l.logger.SubsequentLine(format, args...)
+}
+
+func (l DownloadLayer) partial(artifact string) string {
+	return filepath.Join(filepath.Dir(artifact), fmt.Sprintf(".%s.partial", filepath.Base(artifact)))
+}
+
+func (l DownloadLayer) removePartial(file string) {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		l.logger.Debug("Unable to remove partial download %s: %s", file, err.Error())
+	}
 }
 
+// verify verifies file against the strongest checksum declared by the dependency.
 func (l DownloadLayer) verify(file string) error {
-	s := sha256.New()
+	c, ok := l.dependency.StrongestChecksum()
+	if !ok {
+		return fmt.Errorf("dependency %s does not declare a strong checksum", l.dependency.ID)
+	}
+
+	h, err := c.Hash()
+	if err != nil {
+		return err
+	}
 
 	f, err := os.Open(file)
 	if err != nil {
@@ -122,16 +399,60 @@ func (l DownloadLayer) verify(file string) error {
 	}
 	defer f.Close()
 
-	_, err = io.Copy(s, f)
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+
+	if actual != c.Hex() {
+		return fmt.Errorf("dependency %[1]s mismatch: expected %[1]s %[2]s, actual %[1]s %[3]s",
+			c.Algorithm(), c.Hex(), actual)
+	}
+	return nil
+}
+
+// verifySignature verifies file against the dependency's detached signature using a public key shipped in the
+// buildpack.
+func (l DownloadLayer) verifySignature(file string) error {
+	s := l.dependency.Signature
+
+	if l.offline {
+		if u, err := url.Parse(s.URI); err != nil || u.Scheme != "file" {
+			return OfflineError{l.dependency}
+		}
+	}
+
+	key, err := ioutil.ReadFile(filepath.Join(l.keyRoot, s.Key))
 	if err != nil {
+		return fmt.Errorf("unable to read signature key for dependency %s: %s", l.dependency.ID, err.Error())
+	}
+
+	sig := fmt.Sprintf("%s.sig", file)
+	defer l.removePartial(sig)
+
+	if err := l.download(s.URI, sig); err != nil {
 		return err
 	}
 
-	actualSha256 := hex.EncodeToString(s.Sum(nil))
+	signature, err := ioutil.ReadFile(sig)
+	if err != nil {
+		return err
+	}
 
-	if actualSha256 != l.dependency.SHA256 {
-		return fmt.Errorf("dependency sha256 mismatch: expected sha256 %s, actual sha256 %s",
-			l.dependency.SHA256, actualSha256)
+	if err := verifySignature(file, signature, key); err != nil {
+		return fmt.Errorf("dependency %s signature verification failed: %s", l.dependency.ID, err.Error())
 	}
+
 	return nil
 }
+
+type retryableError struct {
+	error
+}
+
+func isRetryable(err error) bool {
+	_, ok := err.(retryableError)
+	return ok
+}
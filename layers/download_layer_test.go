@@ -17,13 +17,24 @@
 package layers_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	layersBp "github.com/buildpack/libbuildpack/layers"
 	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/helper"
 	"github.com/heroku/libhkbuildpack/internal"
 	"github.com/heroku/libhkbuildpack/layers"
 	"github.com/heroku/libhkbuildpack/logger"
@@ -35,7 +46,7 @@ import (
 )
 
 func TestDownloadLayer(t *testing.T) {
-	spec.Run(t, "DownloadLayer", func(t *testing.T, _ spec.G, it spec.S) {
+	spec.Run(t, "DownloadLayer", func(t *testing.T, when spec.G, it spec.S) {
 
 		g := NewGomegaWithT(t)
 
@@ -58,8 +69,9 @@ func TestDownloadLayer(t *testing.T) {
 				URI:     fmt.Sprintf("%s/test-path", server.URL()),
 			}
 
-			layers := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
-			layer = layers.DownloadLayer(dependency)
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			l.DownloadRetry = layers.Retry{Attempts: 3, Backoff: time.Millisecond}
+			layer = l.DownloadLayer(dependency)
 		})
 
 		it.After(func() {
@@ -109,5 +121,273 @@ URI = "%s"`, dependency.ID, dependency.Version.Original(), dependency.SHA256, de
 
 			g.Expect(filepath.Join(layer.Root, "test-file")).NotTo(BeAnExistingFile())
 		})
+
+		it("retries a failed download", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+				ghttp.RespondWith(http.StatusOK, "test-payload"))
+
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+			g.Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		it("does not retry a client error", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, ""))
+
+			_, err := layer.Artifact()
+			g.Expect(err).To(MatchError("could not download: 404"))
+			g.Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		it("resumes a partial download", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusPartialContent, "-payload"))
+			test.WriteFile(t, filepath.Join(layer.Root, ".test-path.partial"), "test")
+
+			g.Expect(layer.Artifact()).To(SatisfyAll(
+				Equal(filepath.Join(layer.Root, "test-path")),
+				test.HaveContent("test-payload")))
+			g.Expect(server.ReceivedRequests()[0].Header.Get("Range")).To(Equal("bytes=4-"))
+			g.Expect(filepath.Join(layer.Root, ".test-path.partial")).NotTo(BeAnExistingFile())
+		})
+
+		it("downloads a dependency from a mirror", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+			layer = mirroredLayer(root, dependency, layers.Mirrors{server.URL() + "/": server.URL() + "/mirror/"})
+
+			g.Expect(layer.Artifact()).To(SatisfyAll(
+				Equal(filepath.Join(layer.Root, "test-path")),
+				test.HaveContent("test-payload")))
+			g.Expect(server.ReceivedRequests()[0].URL.Path).To(Equal("/mirror/test-path"))
+		})
+
+		it("falls back to upstream when a mirror fails", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, ""),
+				ghttp.RespondWith(http.StatusOK, "test-payload"))
+			layer = mirroredLayer(root, dependency, layers.Mirrors{server.URL() + "/": server.URL() + "/mirror/"})
+
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+			g.Expect(server.ReceivedRequests()[0].URL.Path).To(Equal("/mirror/test-path"))
+			g.Expect(server.ReceivedRequests()[1].URL.Path).To(Equal("/test-path"))
+		})
+
+		it("falls back to upstream when a mirror has an invalid checksum", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, "invalid-payload"),
+				ghttp.RespondWith(http.StatusOK, "test-payload"))
+			layer = mirroredLayer(root, dependency, layers.Mirrors{server.URL() + "/": server.URL() + "/mirror/"})
+
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+			g.Expect(server.ReceivedRequests()[1].URL.Path).To(Equal("/test-path"))
+		})
+
+		it("does not download a dependency in offline mode", func() {
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			l.Offline = true
+			layer = l.DownloadLayer(dependency)
+
+			_, err := layer.Artifact()
+			g.Expect(err).To(Equal(layers.OfflineError{Dependency: dependency}))
+			g.Expect(err).To(MatchError(fmt.Sprintf("dependency test-id 1.0 (sha256 %s) is not cached and offline mode is enabled", dependency.SHA256)))
+			g.Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		it("reuses a cached dependency in offline mode", func() {
+			test.WriteFile(t, filepath.Join(root, "buildpack", fmt.Sprintf("%s.toml", dependency.SHA256)), `[metadata]
+ID = "%s"
+Version = "%s"
+SHA256 = "%s"
+URI = "%s"`, dependency.ID, dependency.Version.Original(), dependency.SHA256, dependency.URI)
+
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			l.Offline = true
+			layer = l.DownloadLayer(dependency)
+
+			g.Expect(layer.Artifact()).To(Equal(filepath.Join(root, "buildpack", dependency.SHA256, "test-path")))
+		})
+
+		it("copies a local dependency in offline mode", func() {
+			test.WriteFile(t, filepath.Join(root, "local", "test-path"), "test-payload")
+			dependency.URI = fmt.Sprintf("file://%s", filepath.Join(root, "local", "test-path"))
+
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			l.Offline = true
+			layer = l.DownloadLayer(dependency)
+
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+		})
+
+		it("authenticates a download without writing credentials", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			c, err := layers.NewCredentials("", func(key string) (string, bool) {
+				return "test-token", key == "BP_DEPENDENCY_TOKEN_127_0_0_1"
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			l.Credentials = c
+			layer = l.DownloadLayer(dependency)
+
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+			g.Expect(server.ReceivedRequests()[0].Header.Get("Authorization")).To(Equal("Bearer test-token"))
+			g.Expect(ioutil.ReadFile(layer.Metadata)).NotTo(ContainSubstring("test-token"))
+		})
+
+		it("downloads with the configured HTTP client", func() {
+			var requests []*http.Request
+
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			c, err := helper.NewHTTPClient(helper.HTTPClientOptions{Transport: roundTripper(func(r *http.Request) (*http.Response, error) {
+				requests = append(requests, r)
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("test-payload")), Request: r}, nil
+			})})
+			g.Expect(err).NotTo(HaveOccurred())
+			l.HTTPClient = c
+			layer = l.DownloadLayer(dependency)
+
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+			g.Expect(requests).To(HaveLen(1))
+			g.Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		when("download cache", func() {
+
+			var cache layers.DownloadCache
+
+			it.Before(func() {
+				cache = layers.DownloadCache{Root: filepath.Join(root, "download-cache")}
+
+				l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+				l.DownloadCache = cache
+				layer = l.DownloadLayer(dependency)
+			})
+
+			it("adds a downloaded dependency to the download cache", func() {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+
+				g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+				g.Expect(filepath.Join(cache.Root, "sha256", dependency.SHA256)).To(test.HaveContent("test-payload"))
+			})
+
+			it("reuses a dependency from the download cache", func() {
+				test.WriteFile(t, filepath.Join(cache.Root, "sha256", dependency.SHA256), "test-payload")
+
+				g.Expect(layer.Artifact()).To(SatisfyAll(
+					Equal(filepath.Join(layer.Root, "test-path")),
+					test.HaveContent("test-payload")))
+				g.Expect(layer).To(test.HaveLayerMetadata(false, true, false))
+				g.Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			it("reuses a dependency from the download cache in offline mode", func() {
+				test.WriteFile(t, filepath.Join(cache.Root, "sha256", dependency.SHA256), "test-payload")
+
+				l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+				l.DownloadCache = cache
+				l.Offline = true
+				layer = l.DownloadLayer(dependency)
+
+				g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+			})
+
+			it("replaces an invalid download cache entry", func() {
+				test.WriteFile(t, filepath.Join(cache.Root, "sha256", dependency.SHA256), "invalid-payload")
+				server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+
+				g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+				g.Expect(filepath.Join(cache.Root, "sha256", dependency.SHA256)).To(test.HaveContent("test-payload"))
+			})
+		})
+
+		it("verifies the strongest checksum", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+			dependency.SHA256 = ""
+			dependency.SHA512 = "41ee5b304e3896fd496bf0193d9f2b5cc4ba74e740bfb0e33c7b9d6e8b6a49d9983586095a3c377bd2447f1f39acb6fcd8f83c95a0d7c3ef7050f32e2c29db77"
+			dependency.Checksum = "sha1:0000000000000000000000000000000000000000"
+
+			l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+			layer = l.DownloadLayer(dependency)
+
+			g.Expect(layer.Root).To(Equal(filepath.Join(root, dependency.SHA512)))
+			g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+		})
+
+		when("signed", func() {
+
+			var bp buildpack.Buildpack
+
+			it.Before(func() {
+				key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				digest := sha256.Sum256([]byte("test-payload"))
+				signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+				g.Expect(err).NotTo(HaveOccurred())
+
+				public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				bp.Root = filepath.Join(root, "buildpack-root")
+				test.WriteFile(t, filepath.Join(bp.Root, "keys", "test-key.pem"), "%s",
+					pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+				test.WriteFile(t, filepath.Join(root, "signatures", "test-path.sig"), "%s",
+					base64.StdEncoding.EncodeToString(signature))
+			})
+
+			it("verifies the signature", func() {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+				dependency.Signature = &buildpack.Signature{
+					URI: fmt.Sprintf("file://%s", filepath.Join(root, "signatures", "test-path.sig")),
+					Key: "keys/test-key.pem",
+				}
+
+				layer = layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, bp, &logger.Log{}).
+					DownloadLayer(dependency)
+
+				g.Expect(layer.Artifact()).To(test.HaveContent("test-payload"))
+				g.Expect(filepath.Join(layer.Root, ".test-path.partial.sig")).NotTo(BeAnExistingFile())
+				g.Expect(layer.MetadataMatches(dependency)).To(BeTrue())
+			})
+
+			it("does not write artifact with invalid signature", func() {
+				server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+				test.WriteFile(t, filepath.Join(root, "signatures", "invalid.sig"), "invalid-signature")
+				dependency.Signature = &buildpack.Signature{
+					URI: fmt.Sprintf("file://%s", filepath.Join(root, "signatures", "invalid.sig")),
+					Key: "keys/test-key.pem",
+				}
+
+				layer = layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, bp, &logger.Log{}).
+					DownloadLayer(dependency)
+
+				_, err := layer.Artifact()
+				g.Expect(err).To(MatchError("dependency test-id signature verification failed: invalid signature"))
+				g.Expect(filepath.Join(layer.Root, "test-path")).NotTo(BeAnExistingFile())
+				g.Expect(layer.Metadata).NotTo(BeAnExistingFile())
+			})
+		})
+
+		it("does not write artifact with invalid checksum", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "invalid-payload"))
+
+			_, err := layer.Artifact()
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(filepath.Join(layer.Root, "test-path")).NotTo(BeAnExistingFile())
+			g.Expect(filepath.Join(layer.Root, ".test-path.partial")).NotTo(BeAnExistingFile())
+			g.Expect(layer.Metadata).NotTo(BeAnExistingFile())
+		})
 	}, spec.Report(report.Terminal{}))
 }
+
+func mirroredLayer(root string, dependency buildpack.Dependency, mirrors layers.Mirrors) layers.DownloadLayer {
+	l := layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+	l.DependencyMirrors = mirrors
+	l.DownloadRetry = layers.Retry{Attempts: 1}
+	return l.DownloadLayer(dependency)
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r(req)
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"sync"
+)
+
+// DefaultDownloadConcurrency is the maximum number of concurrent downloads used by Layers unless otherwise configured.
+const DefaultDownloadConcurrency = 4
+
+// DownloadAll returns the paths to the artifacts of a collection of DownloadLayers, downloading at most concurrency
+// artifacts at a time.  Artifacts are returned in the same order as the layers.  Layers that share a root (e.g. the
+// same artifact for multiple stacks) are downloaded serially.  If any download fails, the error of the first failing
+// layer is returned once all downloads have completed.
+func DownloadAll(downloadLayers []DownloadLayer, concurrency int) ([]string, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	artifacts := make([]string, len(downloadLayers))
+	errs := make([]error, len(downloadLayers))
+
+	var roots []string
+	groups := make(map[string][]int)
+	for i, dl := range downloadLayers {
+		if _, ok := groups[dl.Root]; !ok {
+			roots = append(roots, dl.Root)
+		}
+		groups[dl.Root] = append(groups[dl.Root], i)
+	}
+
+	interleaved := concurrency > 1 && len(roots) > 1
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, root := range roots {
+		wg.Add(1)
+
+		go func(indices []int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			for _, i := range indices {
+				dl := downloadLayers[i]
+				if interleaved {
+					dl.label = dl.logger.PrettyIdentity(dl.dependency)
+				}
+
+				if artifacts[i], errs[i] = dl.Artifact(); errs[i] != nil {
+					return
+				}
+			}
+		}(groups[root])
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return artifacts, nil
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	layersBp "github.com/buildpack/libbuildpack/layers"
+	"github.com/buildpack/libbuildpack/stack"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestDownloadAll(t *testing.T) {
+	spec.Run(t, "DownloadAll", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			info   bytes.Buffer
+			ls     layers.Layers
+			server *ghttp.Server
+		)
+
+		dependency := func(id string, s string) buildpack.Dependency {
+			sum := sha256.Sum256([]byte(id))
+
+			return buildpack.Dependency{
+				ID:      id,
+				Name:    id,
+				Version: internal.NewTestVersion(t, "1.0"),
+				SHA256:  hex.EncodeToString(sum[:]),
+				URI:     fmt.Sprintf("%s/%s", server.URL(), id),
+				Stacks:  buildpack.Stacks{stack.Stack(s)},
+			}
+		}
+
+		it.Before(func() {
+			root := test.ScratchDir(t, "download-all")
+
+			server = ghttp.NewServer()
+			server.SetAllowUnhandledRequests(true)
+			server.RouteToHandler("GET", "/test-id-1", ghttp.RespondWith(http.StatusOK, "test-id-1"))
+			server.RouteToHandler("GET", "/test-id-2", ghttp.RespondWith(http.StatusOK, "test-id-2"))
+			server.RouteToHandler("GET", "/test-id-3", ghttp.RespondWith(http.StatusOK, "test-id-3"))
+
+			info.Reset()
+			ls = layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")},
+				buildpack.Buildpack{}, logger.NewFromWriters(nil, &info))
+			ls.DownloadRetry = layers.Retry{Attempts: 1}
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("returns artifacts in layer order", func() {
+			var dls []layers.DownloadLayer
+			for _, id := range []string{"test-id-1", "test-id-2", "test-id-3"} {
+				dls = append(dls, ls.DownloadLayer(dependency(id, "test-stack")))
+			}
+
+			artifacts, err := layers.DownloadAll(dls, 2)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(artifacts).To(HaveLen(3))
+			g.Expect(artifacts[0]).To(test.HaveContent("test-id-1"))
+			g.Expect(artifacts[1]).To(test.HaveContent("test-id-2"))
+			g.Expect(artifacts[2]).To(test.HaveContent("test-id-3"))
+		})
+
+		it("labels interleaved progress with the dependency", func() {
+			dls := []layers.DownloadLayer{
+				ls.DownloadLayer(dependency("test-id-1", "test-stack")),
+				ls.DownloadLayer(dependency("test-id-2", "test-stack")),
+			}
+
+			_, err := layers.DownloadAll(dls, 2)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, line := range strings.Split(strings.TrimSuffix(info.String(), "\n"), "\n") {
+				g.Expect(line).To(MatchRegexp(`^\s+test-id-[12] - 1\.0: `))
+			}
+		})
+
+		it("downloads layers sharing a root serially", func() {
+			dls := []layers.DownloadLayer{
+				ls.DownloadLayer(dependency("test-id-1", "test-stack-1")),
+				ls.DownloadLayer(dependency("test-id-1", "test-stack-2")),
+			}
+
+			artifacts, err := layers.DownloadAll(dls, 2)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(artifacts[0]).To(Equal(artifacts[1]))
+			g.Expect(artifacts[1]).To(test.HaveContent("test-id-1"))
+		})
+
+		it("notifies as each download starts", func() {
+			var (
+				mutex   sync.Mutex
+				started []string
+			)
+			ls.DownloadStarted = func(dependency buildpack.Dependency) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				started = append(started, dependency.ID)
+			}
+
+			var dls []layers.DownloadLayer
+			for _, id := range []string{"test-id-1", "test-id-2", "test-id-3"} {
+				dls = append(dls, ls.DownloadLayer(dependency(id, "test-stack")))
+			}
+
+			_, err := layers.DownloadAll(dls, 2)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(started).To(ConsistOf("test-id-1", "test-id-2", "test-id-3"))
+		})
+
+		it("returns the first error", func() {
+			missing := dependency("test-id-4", "test-stack")
+
+			_, err := layers.DownloadAll([]layers.DownloadLayer{
+				ls.DownloadLayer(dependency("test-id-1", "test-stack")),
+				ls.DownloadLayer(missing),
+			}, 2)
+			g.Expect(err).To(MatchError("could not download: 500"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/heroku/libhkbuildpack/helper"
+)
+
+// Scope is the environment an environment variable is contributed to.
+type Scope string
+
+const (
+	// BuildScope contributes an environment variable to subsequent buildpacks during build.
+	BuildScope Scope = "env.build"
+
+	// LaunchScope contributes an environment variable to all processes at launch.
+	LaunchScope Scope = "env.launch"
+
+	// SharedScope contributes an environment variable to both build and launch.
+	SharedScope Scope = "env"
+)
+
+// ProcessScope returns the scope that contributes an environment variable only to a single process type at launch.
+func ProcessScope(process string) Scope {
+	return Scope(fmt.Sprintf("%s/%s", string(LaunchScope), process))
+}
+
+var processType = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (s Scope) String() string {
+	switch s {
+	case BuildScope:
+		return "build"
+	case LaunchScope:
+		return "launch"
+	case SharedScope:
+		return "shared"
+	default:
+		return fmt.Sprintf("launch (%s)", strings.TrimPrefix(string(s), string(LaunchScope)+"/"))
+	}
+}
+
+func (s Scope) validate() error {
+	switch s {
+	case BuildScope, LaunchScope, SharedScope:
+		return nil
+	}
+
+	if p := strings.TrimPrefix(string(s), string(LaunchScope)+"/"); p != string(s) && processType.MatchString(p) {
+		return nil
+	}
+
+	return fmt.Errorf("invalid environment scope %q", string(s))
+}
+
+// EnvAction is how an environment variable is combined with any previous value.
+type EnvAction string
+
+const (
+	// EnvAppend appends the value to any previous value, separated by the variable's delimiter if one is declared.
+	EnvAppend EnvAction = "append"
+
+	// EnvDefault sets the value only if the variable is not already set.
+	EnvDefault EnvAction = "default"
+
+	// EnvOverride replaces any previous value.
+	EnvOverride EnvAction = "override"
+
+	// EnvPrepend prepends the value to any previous value, separated by the variable's delimiter if one is declared.
+	EnvPrepend EnvAction = "prepend"
+)
+
+// WriteEnv writes an environment variable to a scope, combined with any previous value using action.
+func (l Layer) WriteEnv(scope Scope, action EnvAction, name string, format string, args ...interface{}) error {
+	return l.writeEnv(scope, name, envFile{fmt.Sprintf("%s.%s", name, action), fmt.Sprintf(format, args...)})
+}
+
+// WriteEnvMap writes all environment variables in env to a scope, combined with any previous values using action.
+// Variables are written in name order.
+func (l Layer) WriteEnvMap(scope Scope, action EnvAction, env map[string]string) error {
+	var names []string
+	for n := range env {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		if err := l.WriteEnv(scope, action, n, "%s", env[n]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DefaultEnv sets an environment variable in a scope only if it is not already set.
+func (l Layer) DefaultEnv(scope Scope, name string, format string, args ...interface{}) error {
+	return l.WriteEnv(scope, EnvDefault, name, format, args...)
+}
+
+// PrependPathEnv prepends the value of an environment variable in a scope to any previous value using the OS path
+// delimiter (e.g. to put a directory first on the PATH).
+func (l Layer) PrependPathEnv(scope Scope, name string, format string, args ...interface{}) error {
+	return l.writeEnv(scope, name,
+		envFile{fmt.Sprintf("%s.%s", name, EnvPrepend), fmt.Sprintf(format, args...)},
+		envFile{fmt.Sprintf("%s.delim", name), string(os.PathListSeparator)})
+}
+
+// envFile is a file that declares part of an environment variable.
+type envFile struct {
+	name    string
+	content string
+}
+
+// writeEnv writes the files of an environment variable to a scope.  In plan mode, the variable is recorded instead.
+func (l Layer) writeEnv(scope Scope, name string, files ...envFile) error {
+	l.Touch()
+
+	if err := scope.validate(); err != nil {
+		return err
+	}
+
+	if l.planned(PlanEnvironment, "%s to %s", name, scope) {
+		return nil
+	}
+
+	l.Logger.SubsequentLine("Writing %s to %s", name, scope)
+
+	for _, e := range files {
+		f := filepath.Join(l.Root, string(scope), e.name)
+
+		if l.Logger.IsDebugEnabled() {
+			l.Logger.Debug("Writing environment variable: %s <= %s", f, e.content)
+		}
+
+		if err := helper.WriteFile(f, 0644, "%s", e.content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	bp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestEnvironment(t *testing.T) {
+	spec.Run(t, "Environment", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			l     layers.Layers
+			layer layers.Layer
+		)
+
+		it.Before(func() {
+			l = layers.NewLayers(bp.Layers{Root: test.ScratchDir(t, "environment")}, bp.Layers{}, buildpack.Buildpack{}, logger.New(nil))
+			layer = l.Layer("test-layer")
+		})
+
+		it("writes environment variable to scope", func() {
+			g.Expect(layer.WriteEnv(layers.BuildScope, layers.EnvOverride, "TEST_NAME", "test-%s", "value")).To(Succeed())
+			g.Expect(layer.WriteEnv(layers.LaunchScope, layers.EnvAppend, "TEST_NAME", "test-value")).To(Succeed())
+			g.Expect(layer.WriteEnv(layers.SharedScope, layers.EnvPrepend, "TEST_NAME", "test-value")).To(Succeed())
+
+			g.Expect(layer).To(test.HaveOverrideBuildEnvironment("TEST_NAME", "test-value"))
+			g.Expect(layer).To(test.HaveAppendLaunchEnvironment("TEST_NAME", "test-value"))
+			g.Expect(filepath.Join(layer.Root, "env", "TEST_NAME.prepend")).To(test.HaveContent("test-value"))
+		})
+
+		it("writes default environment variable", func() {
+			g.Expect(layer.DefaultEnv(layers.LaunchScope, "TEST_NAME", "test-value")).To(Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "env.launch", "TEST_NAME.default")).To(test.HaveContent("test-value"))
+		})
+
+		it("prepends path environment variable", func() {
+			g.Expect(layer.PrependPathEnv(layers.SharedScope, "PATH", "%s", filepath.Join(layer.Root, "bin"))).To(Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "env", "PATH.prepend")).To(test.HaveContent(filepath.Join(layer.Root, "bin")))
+			g.Expect(filepath.Join(layer.Root, "env", "PATH.delim")).To(test.HaveContent(":"))
+		})
+
+		it("writes process environment variable", func() {
+			g.Expect(layer.WriteEnv(layers.ProcessScope("web"), layers.EnvOverride, "TEST_NAME", "test-value")).To(Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "env.launch", "web", "TEST_NAME.override")).To(test.HaveContent("test-value"))
+		})
+
+		it("returns error for invalid process", func() {
+			g.Expect(layer.WriteEnv(layers.ProcessScope("../web"), layers.EnvOverride, "TEST_NAME", "test-value")).
+				To(MatchError(`invalid environment scope "env.launch/../web"`))
+		})
+
+		it("writes environment map", func() {
+			g.Expect(layer.WriteEnvMap(layers.BuildScope, layers.EnvDefault, map[string]string{
+				"TEST_ALPHA": "test-alpha",
+				"TEST_BRAVO": "test-bravo",
+			})).To(Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "env.build", "TEST_ALPHA.default")).To(test.HaveContent("test-alpha"))
+			g.Expect(filepath.Join(layer.Root, "env.build", "TEST_BRAVO.default")).To(test.HaveContent("test-bravo"))
+		})
+
+		it("touches layer", func() {
+			g.Expect(layer.WriteEnv(layers.BuildScope, layers.EnvOverride, "TEST_NAME", "test-value")).To(Succeed())
+
+			g.Expect(l.TouchedLayers.Cleanup()).To(Succeed())
+			g.Expect(filepath.Join(layer.Root, "env.build", "TEST_NAME.override")).To(BeARegularFile())
+		})
+	}, spec.Report(report.Terminal{}))
+}
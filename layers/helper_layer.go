@@ -23,6 +23,7 @@ import (
 	"github.com/buildpack/libbuildpack/buildplan"
 	"github.com/heroku/libhkbuildpack/buildpack"
 	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/sbom"
 )
 
 // HelperLayer is an extension to Layer that is unique to a buildpack provided helper.
@@ -33,7 +34,7 @@ type HelperLayer struct {
 	ID string
 
 	buildpack            buildpack.Buildpack
-	dependencyBuildPlans buildplan.BuildPlan
+	dependencyBuildPlans buildPlans
 	name                 string
 	logger               *logger.Log
 }
@@ -44,7 +45,8 @@ type HelperLayerContributor func(artifact string, layer HelperLayer) error
 
 // Contribute facilitates custom contribution of a buildpack provided helper to a layer.  If the artifact has already
 // been contributed, the contribution is validated and the contributor is not called.  If the contribution is out of
-// date, the layer is completely removed before contribution occurs.
+// date, the layer is completely removed before contribution occurs.  A bill-of-materials for the helper is written
+// alongside the layer metadata.
 func (l HelperLayer) Contribute(contributor HelperLayerContributor, flags ...Flag) error {
 	if err := l.Layer.Contribute(marker{l.buildpack.Info, l.name}, func(layer Layer) error {
 		if err := os.RemoveAll(l.Root); err != nil {
@@ -59,19 +61,19 @@ func (l HelperLayer) Contribute(contributor HelperLayerContributor, flags ...Fla
 	}
 
 	l.contributeToBuildPlan()
-	return nil
+	return l.WriteSBOM(sbom.Component{ID: l.ID, Name: l.name, Version: l.buildpack.Info.Version})
 }
 
 func (l *HelperLayer) contributeToBuildPlan() {
 	l.logger.Debug("Contributing %s to bill-of-materials", l.ID)
 
-	l.dependencyBuildPlans[l.ID] = buildplan.Dependency{
+	l.dependencyBuildPlans.add(l.ID, buildplan.Dependency{
 		Version: l.buildpack.Info.Version,
 		Metadata: buildplan.Metadata{
 			"id":   l.buildpack.Info.ID,
 			"name": l.buildpack.Info.Name,
 		},
-	}
+	})
 }
 
 type marker struct {
@@ -17,6 +17,7 @@
 package layers_test
 
 import (
+	"io/ioutil"
 	"path/filepath"
 	"testing"
 
@@ -68,6 +69,14 @@ func TestHelperLayer(t *testing.T) {
 			g.Expect(layer.Root).To(Equal(filepath.Join(root, id)))
 		})
 
+		it("writes bills-of-materials", func() {
+			g.Expect(layer.Contribute(func(artifact string, layer layers.HelperLayer) error {
+				return nil
+			})).To(Succeed())
+
+			g.Expect(ioutil.ReadFile(filepath.Join(root, "test-id.sbom.spdx.json"))).To(ContainSubstring(`"name": "Test Name"`))
+		})
+
 		it("calls contributor to contribute dependency layer", func() {
 			contributed := false
 			g.Expect(layer.Contribute(func(artifact string, layer layers.HelperLayer) error {
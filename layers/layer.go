@@ -17,9 +17,13 @@
 package layers
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 
 	"github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/helper"
 	"github.com/heroku/libhkbuildpack/logger"
 )
 
@@ -30,6 +34,8 @@ type Layer struct {
 	// Log is used to write debug and info to the console.
 	Logger *logger.Log
 
+	plan          *Plan
+	summary       *BuildSummary
 	touchedLayers TouchedLayers
 }
 
@@ -37,6 +43,11 @@ type Layer struct {
 // delimitation.  If delimitation is important during concatenation, callers are required to add it.
 func (l Layer) AppendBuildEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to build", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to build", name)
 	return l.Layer.AppendBuildEnv(name, format, args...)
 }
@@ -45,6 +56,11 @@ func (l Layer) AppendBuildEnv(name string, format string, args ...interface{}) e
 // delimitation.  If delimitation is important during concatenation, callers are required to add it.
 func (l Layer) AppendLaunchEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to launch", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to launch", name)
 	return l.Layer.AppendLaunchEnv(name, format, args...)
 }
@@ -53,6 +69,11 @@ func (l Layer) AppendLaunchEnv(name string, format string, args ...interface{})
 // delimitation.  If delimitation is important during concatenation, callers are required to add it.
 func (l Layer) AppendSharedEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to shared", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to shared", name)
 	return l.Layer.AppendSharedEnv(name, format, args...)
 }
@@ -61,6 +82,11 @@ func (l Layer) AppendSharedEnv(name string, format string, args ...interface{})
 // OS path delimiter.
 func (l Layer) AppendPathBuildEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to build", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to build", name)
 	return l.Layer.AppendPathBuildEnv(name, format, args...)
 }
@@ -69,6 +95,11 @@ func (l Layer) AppendPathBuildEnv(name string, format string, args ...interface{
 // the OS path delimiter.
 func (l Layer) AppendPathLaunchEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to launch", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to launch", name)
 	return l.Layer.AppendPathLaunchEnv(name, format, args...)
 }
@@ -77,6 +108,11 @@ func (l Layer) AppendPathLaunchEnv(name string, format string, args ...interface
 // the OS path delimiter.
 func (l Layer) AppendPathSharedEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to shared", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to shared", name)
 	return l.Layer.AppendPathSharedEnv(name, format, args...)
 }
@@ -84,6 +120,11 @@ func (l Layer) AppendPathSharedEnv(name string, format string, args ...interface
 // OverrideBuildEnv overrides any existing value for an environment variable with this value.
 func (l Layer) OverrideBuildEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to build", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to build", name)
 	return l.Layer.OverrideBuildEnv(name, format, args...)
 }
@@ -91,6 +132,11 @@ func (l Layer) OverrideBuildEnv(name string, format string, args ...interface{})
 // OverrideLaunchEnv overrides any existing value for an environment variable with this value.
 func (l Layer) OverrideLaunchEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to launch", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to launch", name)
 	return l.Layer.OverrideLaunchEnv(name, format, args...)
 }
@@ -98,6 +144,11 @@ func (l Layer) OverrideLaunchEnv(name string, format string, args ...interface{}
 // OverrideSharedEnv overrides any existing value for an environment variable with this value.
 func (l Layer) OverrideSharedEnv(name string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "%s to shared", name) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing %s to shared", name)
 	return l.Layer.OverrideSharedEnv(name, format, args...)
 }
@@ -106,8 +157,10 @@ func (l Layer) OverrideSharedEnv(name string, format string, args ...interface{}
 type LayerContributor func(layer Layer) error
 
 // Contribute facilitates custom contribution of a layer.  If the layer has already been contributed, the contribution
-// is validated and the contributor is not called.  If the contribution is out of date, the layer is
-// // completely removed before contribution occurs.
+// is validated and the contributor is not called.  If the contribution is out of date, the contributor is called with
+// an empty layer and the previous layer is only removed once the contributor and metadata have been written
+// successfully.  If either fails, the partial contribution is removed and the previous layer is restored.  In plan
+// mode, the contribution or reuse is recorded and the contributor is not called.
 func (l Layer) Contribute(expected logger.Identifiable, contributor LayerContributor, flags ...Flag) error {
 	l.Touch()
 
@@ -116,21 +169,123 @@ func (l Layer) Contribute(expected logger.Identifiable, contributor LayerContrib
 		return err
 	}
 
+	return l.contribute(expected, expected, matches, contributor, flags...)
+}
+
+func (l Layer) contribute(expected logger.Identifiable, metadata interface{}, matches bool, contributor LayerContributor, flags ...Flag) error {
+	if l.planning() {
+		if matches {
+			l.planned(PlanReuse, "%s", l.Logger.PrettyIdentity(expected))
+		} else {
+			l.planned(PlanContribute, "%s", l.Logger.PrettyIdentity(expected))
+		}
+
+		return nil
+	}
+
 	if matches {
 		l.Logger.FirstLine("%s: %s cached layer",
 			l.Logger.PrettyIdentity(expected), "Reusing")
-		return l.WriteMetadata(expected, flags...)
+
+		if err := l.WriteMetadata(metadata, flags...); err != nil {
+			return err
+		}
+
+		return l.recordSize(flags...)
 	}
 
 	l.Logger.FirstLine("%s: %s to layer",
 		l.Logger.PrettyIdentity(expected), "Contributing")
 
+	previous, err := l.stage()
+	if err != nil {
+		return err
+	}
+
 	if err := contributor(l); err != nil {
 		l.Logger.Debug("Error during contribution")
+		return l.rollback(previous, err)
+	}
+
+	if err := l.WriteMetadata(metadata, flags...); err != nil {
+		return l.rollback(previous, err)
+	}
+
+	if err := os.RemoveAll(previous.Root); err != nil {
 		return err
 	}
 
-	return l.WriteMetadata(expected, flags...)
+	return l.recordSize(flags...)
+}
+
+// recordSize records the size of the layer in the build summary, if there is one.
+func (l Layer) recordSize(flags ...Flag) error {
+	if l.summary == nil {
+		return nil
+	}
+
+	return l.summary.Record(l, flags...)
+}
+
+// stage moves the current contents and metadata of the layer aside so that the contributor starts from an empty
+// layer, returning the location of the previous layer.
+func (l Layer) stage() (layers.Layer, error) {
+	previous := layers.Layer{
+		Root:     filepath.Join(filepath.Dir(l.Root), fmt.Sprintf(".%s.previous", filepath.Base(l.Root))),
+		Metadata: filepath.Join(filepath.Dir(l.Metadata), fmt.Sprintf(".%s.previous", filepath.Base(l.Metadata))),
+	}
+
+	if err := os.RemoveAll(previous.Root); err != nil {
+		return layers.Layer{}, err
+	}
+
+	if err := os.RemoveAll(previous.Metadata); err != nil {
+		return layers.Layer{}, err
+	}
+
+	if err := renameIfExists(l.Metadata, previous.Metadata); err != nil {
+		return layers.Layer{}, err
+	}
+
+	if err := renameIfExists(l.Root, previous.Root); err != nil {
+		return layers.Layer{}, err
+	}
+
+	return previous, nil
+}
+
+// rollback replaces a partially contributed layer with the previous layer, returning the contribution error.
+func (l Layer) rollback(previous layers.Layer, cause error) error {
+	l.Logger.Debug("Restoring previous layer %s", l.Root)
+
+	if err := os.RemoveAll(l.Root); err != nil {
+		l.Logger.Warning("Unable to remove partial layer %s: %s", l.Root, err.Error())
+		return cause
+	}
+
+	if err := os.RemoveAll(l.Metadata); err != nil {
+		l.Logger.Warning("Unable to remove partial layer metadata %s: %s", l.Metadata, err.Error())
+		return cause
+	}
+
+	if err := renameIfExists(previous.Root, l.Root); err != nil {
+		l.Logger.Warning("Unable to restore previous layer %s: %s", l.Root, err.Error())
+		return cause
+	}
+
+	if err := renameIfExists(previous.Metadata, l.Metadata); err != nil {
+		l.Logger.Warning("Unable to restore previous layer metadata %s: %s", l.Metadata, err.Error())
+	}
+
+	return cause
+}
+
+func renameIfExists(source string, destination string) error {
+	if err := os.Rename(source, destination); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
 }
 
 // MetadataMatches compares the expected metadata for the actual metadata of this layer.
@@ -145,6 +300,7 @@ func (l Layer) MetadataMatches(expected interface{}) (bool, error) {
 
 	if err := l.ReadMetadata(actual); err != nil {
 		l.Logger.Debug("Dependency metadata is not structured correctly: %s", err.Error())
+		l.logMetadataDiff(expected)
 		return false, nil
 	}
 
@@ -154,19 +310,86 @@ func (l Layer) MetadataMatches(expected interface{}) (bool, error) {
 	matches := reflect.DeepEqual(actual, e2.Interface())
 	if !matches {
 		l.Logger.Debug("Layer metadata %s does not match expected %s", actual, expected)
+		l.logMetadataDiff(expected)
 	}
 
 	return matches, nil
 }
 
+// planning returns whether the layer is in plan mode.
+func (l Layer) planning() bool {
+	return l.plan != nil
+}
+
+// planned records an action in plan mode, returning whether the action must be skipped.
+func (l Layer) planned(action string, format string, args ...interface{}) bool {
+	if !l.planning() {
+		return false
+	}
+
+	l.plan.Record(filepath.Base(l.Root), action, format, args...)
+	return true
+}
+
 // Touch touches a layer, indicating that it was used and should not be removed.
 func (l Layer) Touch() {
 	l.touchedLayers.Add(l.Metadata)
 }
 
+// WriteExecD copies an executable to exec.d so that it is run before every process at launch.  exec.d executables emit
+// environment variables as TOML (see the execd package) and, unlike profile.d scripts, do not require a shell.
+func (l Layer) WriteExecD(name string, executable string) error {
+	l.Touch()
+
+	if l.planned(PlanEnvironment, "exec.d/%s", name) {
+		return nil
+	}
+
+	l.Logger.SubsequentLine("Writing exec.d/%s", name)
+	return l.writeExecD(filepath.Join("exec.d", name), executable)
+}
+
+// WriteProcessExecD copies an executable to exec.d so that it is run before a single process type at launch.
+func (l Layer) WriteProcessExecD(process string, name string, executable string) error {
+	l.Touch()
+
+	if !processType.MatchString(process) {
+		return fmt.Errorf("invalid process type %q", process)
+	}
+
+	if l.planned(PlanEnvironment, "exec.d/%s/%s", process, name) {
+		return nil
+	}
+
+	l.Logger.SubsequentLine("Writing exec.d/%s/%s", process, name)
+	return l.writeExecD(filepath.Join("exec.d", process, name), executable)
+}
+
+func (l Layer) writeExecD(file string, executable string) error {
+	f := filepath.Join(l.Root, file)
+
+	in, err := os.Open(executable)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	l.Logger.Debug("Writing exec.d executable: %s <= %s", f, executable)
+	if err := helper.WriteFileFromReader(f, 0755, in); err != nil {
+		return err
+	}
+
+	return os.Chmod(f, 0755)
+}
+
 // WriteProfile writes a file to profile.d with this value.
 func (l Layer) WriteProfile(file string, format string, args ...interface{}) error {
 	l.Touch()
+
+	if l.planned(PlanEnvironment, "profile.d/%s", file) {
+		return nil
+	}
+
 	l.Logger.SubsequentLine("Writing .profile.d/%s", file)
 	return l.Layer.WriteProfile(file, format, args...)
 }
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/heroku/libhkbuildpack/logger"
+)
+
+// InputsMetadata is the layer metadata written by ContributeInputs.  It combines the expected metadata with a digest
+// of the contribution's input files.
+type InputsMetadata struct {
+	// Digest is the SHA256 digest of the input files.
+	Digest string `toml:"digest"`
+
+	// Metadata is the expected metadata of the layer.
+	Metadata logger.Identifiable `toml:"metadata"`
+}
+
+// Identity make InputsMetadata satisfy the logger.Identifiable interface.
+func (i InputsMetadata) Identity() (string, string) {
+	return i.Metadata.Identity()
+}
+
+// ContributeInputs facilitates custom contribution of a layer derived from input files.  inputs are file paths or
+// glob patterns; directories are included recursively.  If the layer has already been contributed with the same
+// expected metadata and the same input digest, the contribution is validated and the contributor is not called.
+func (l Layer) ContributeInputs(expected logger.Identifiable, inputs []string, contributor LayerContributor, flags ...Flag) error {
+	l.Touch()
+
+	digest, err := InputsDigest(inputs...)
+	if err != nil {
+		return err
+	}
+
+	metadata := InputsMetadata{digest, expected}
+
+	matches, err := l.inputsMatch(metadata)
+	if err != nil {
+		return err
+	}
+
+	return l.contribute(expected, metadata, matches, contributor, flags...)
+}
+
+func (l Layer) inputsMatch(expected InputsMetadata) (bool, error) {
+	var actual map[string]interface{}
+	if err := l.ReadMetadata(&actual); err != nil {
+		l.Logger.Debug("Inputs metadata is not structured correctly: %s", err.Error())
+		return false, nil
+	}
+
+	e, err := tomlMap(expected)
+	if err != nil {
+		return false, err
+	}
+
+	matches := reflect.DeepEqual(actual, e)
+	if !matches {
+		l.Logger.Debug("Layer metadata %s does not match expected %s", actual, e)
+		l.logMetadataDiff(expected)
+	}
+
+	return matches, nil
+}
+
+// InputsDigest returns a stable SHA256 digest of the files matching inputs.  inputs are file paths or glob patterns;
+// directories are included recursively.  Both the paths and contents of files contribute to the digest, and patterns
+// that match no files are ignored.
+func InputsDigest(inputs ...string) (string, error) {
+	var files []string
+
+	for _, i := range inputs {
+		matches, err := filepath.Glob(i)
+		if err != nil {
+			return "", fmt.Errorf("invalid input pattern %q: %s", i, err.Error())
+		}
+
+		for _, m := range matches {
+			if err := filepath.Walk(m, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if !info.IsDir() {
+					files = append(files, path)
+				}
+
+				return nil
+			}); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	sort.Strings(files)
+
+	h := sha256.New()
+	previous := ""
+	for _, f := range files {
+		if f == previous {
+			continue
+		}
+		previous = f
+
+		if err := digestFile(h, f); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func digestFile(w io.Writer, file string) error {
+	info, err := os.Lstat(file)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\x00%d\x00", filepath.ToSlash(file), info.Size()); err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(file)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, target)
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
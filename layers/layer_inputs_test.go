@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	bp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestLayerInputs(t *testing.T) {
+	spec.Run(t, "LayerInputs", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			app   string
+			root  string
+			layer layers.Layer
+		)
+
+		it.Before(func() {
+			root = test.ScratchDir(t, "layer-inputs")
+			app = filepath.Join(root, "application")
+			layer = layers.NewLayers(bp.Layers{Root: filepath.Join(root, "layers")}, bp.Layers{}, buildpack.Buildpack{}, logger.New(nil)).Layer("test-layer")
+
+			test.WriteFile(t, filepath.Join(app, "package-lock.json"), "test-lock")
+			test.WriteFile(t, filepath.Join(app, "config", "alpha.yml"), "test-alpha")
+			test.WriteFile(t, filepath.Join(app, "config", "bravo.yml"), "test-bravo")
+		})
+
+		contribute := func(inputs ...string) bool {
+			t.Helper()
+
+			contributed := false
+			g.Expect(layer.ContributeInputs(metadata{"test-value", 1}, inputs, func(layer layers.Layer) error {
+				contributed = true
+				return nil
+			})).To(Succeed())
+
+			return contributed
+		}
+
+		when("InputsDigest", func() {
+
+			it("is stable", func() {
+				d1, err := layers.InputsDigest(filepath.Join(app, "config", "*.yml"), filepath.Join(app, "package-lock.json"))
+				g.Expect(err).NotTo(HaveOccurred())
+
+				d2, err := layers.InputsDigest(filepath.Join(app, "package-lock.json"), filepath.Join(app, "config"))
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(d1).To(Equal(d2))
+			})
+
+			it("changes with content", func() {
+				d1, err := layers.InputsDigest(filepath.Join(app, "package-lock.json"))
+				g.Expect(err).NotTo(HaveOccurred())
+
+				test.WriteFile(t, filepath.Join(app, "package-lock.json"), "test-lock-2")
+
+				g.Expect(layers.InputsDigest(filepath.Join(app, "package-lock.json"))).NotTo(Equal(d1))
+			})
+
+			it("ignores patterns without matches", func() {
+				d, err := layers.InputsDigest(filepath.Join(app, "package-lock.json"))
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(layers.InputsDigest(filepath.Join(app, "package-lock.json"), filepath.Join(app, "*.missing"))).To(Equal(d))
+			})
+
+			it("returns error for invalid pattern", func() {
+				_, err := layers.InputsDigest("[")
+				g.Expect(err).To(MatchError(ContainSubstring(`invalid input pattern "["`)))
+			})
+		})
+
+		it("calls contributor for uncached layer", func() {
+			g.Expect(contribute(filepath.Join(app, "package-lock.json"))).To(BeTrue())
+		})
+
+		it("does not call contributor when inputs are unchanged", func() {
+			g.Expect(contribute(filepath.Join(app, "package-lock.json"))).To(BeTrue())
+			g.Expect(contribute(filepath.Join(app, "package-lock.json"))).To(BeFalse())
+		})
+
+		it("calls contributor when inputs change", func() {
+			g.Expect(contribute(filepath.Join(app, "config", "*.yml"))).To(BeTrue())
+
+			test.WriteFile(t, filepath.Join(app, "config", "charlie.yml"), "test-charlie")
+
+			g.Expect(contribute(filepath.Join(app, "config", "*.yml"))).To(BeTrue())
+		})
+
+		it("calls contributor when metadata changes", func() {
+			g.Expect(contribute(filepath.Join(app, "package-lock.json"))).To(BeTrue())
+
+			contributed := false
+			g.Expect(layer.ContributeInputs(metadata{"test-value", 2}, []string{filepath.Join(app, "package-lock.json")}, func(layer layers.Layer) error {
+				contributed = true
+				return nil
+			})).To(Succeed())
+
+			g.Expect(contributed).To(BeTrue())
+		})
+
+		it("stores digest in metadata", func() {
+			contribute(filepath.Join(app, "package-lock.json"))
+
+			d, err := layers.InputsDigest(filepath.Join(app, "package-lock.json"))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			var m struct{ Digest string }
+			g.Expect(layer.ReadMetadata(&m)).To(Succeed())
+			g.Expect(m.Digest).To(Equal(d))
+		})
+	}, spec.Report(report.Terminal{}))
+}
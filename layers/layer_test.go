@@ -104,14 +104,70 @@ Bravo = 1
 			g.Expect(contributed).To(BeTrue())
 		})
 
-		it("does not clean directory for non-matching metadata", func() {
+		it("contributes to an empty layer for non-matching metadata", func() {
 			test.TouchFile(t, layer.Root, "test-file")
 
 			g.Expect(layer.Contribute(metadata{"test-value", 1}, func(layer layers.Layer) error {
+				g.Expect(filepath.Join(layer.Root, "test-file")).NotTo(BeAnExistingFile())
+				test.TouchFile(t, layer.Root, "other-file")
 				return nil
 			})).To(Succeed())
 
+			g.Expect(filepath.Join(layer.Root, "test-file")).NotTo(BeAnExistingFile())
+			g.Expect(filepath.Join(layer.Root, "other-file")).To(BeARegularFile())
+			g.Expect(filepath.Join(root, ".test-layer.previous")).NotTo(BeAnExistingFile())
+		})
+
+		it("restores previous layer when contributor fails", func() {
+			test.WriteFile(t, layer.Metadata, `[metadata]
+Alpha = "test-value"
+Bravo = 1
+`)
+			test.TouchFile(t, layer.Root, "test-file")
+
+			g.Expect(layer.Contribute(metadata{"test-value", 2}, func(layer layers.Layer) error {
+				test.TouchFile(t, layer.Root, "partial-file")
+				return fmt.Errorf("test-error")
+			})).To(MatchError("test-error"))
+
 			g.Expect(filepath.Join(layer.Root, "test-file")).To(BeARegularFile())
+			g.Expect(filepath.Join(layer.Root, "partial-file")).NotTo(BeAnExistingFile())
+			g.Expect(layer.MetadataMatches(metadata{"test-value", 1})).To(BeTrue())
+			g.Expect(filepath.Join(root, ".test-layer.previous")).NotTo(BeAnExistingFile())
+		})
+
+		it("removes partial layer without previous layer when contributor fails", func() {
+			g.Expect(layer.Contribute(metadata{"test-value", 1}, func(layer layers.Layer) error {
+				test.TouchFile(t, layer.Root, "partial-file")
+				return fmt.Errorf("test-error")
+			})).To(MatchError("test-error"))
+
+			g.Expect(layer.Root).NotTo(BeAnExistingFile())
+			g.Expect(layer.Metadata).NotTo(BeAnExistingFile())
+		})
+
+		it("writes exec.d executable", func() {
+			executable := filepath.Join(root, "test-executable")
+			test.WriteFile(t, executable, "test-content")
+
+			g.Expect(layer.WriteExecD("test-helper", executable)).To(Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "exec.d", "test-helper")).To(test.HaveContent("test-content"))
+			g.Expect(filepath.Join(layer.Root, "exec.d", "test-helper")).To(test.HavePermissions(0755))
+		})
+
+		it("writes process exec.d executable", func() {
+			executable := filepath.Join(root, "test-executable")
+			test.WriteFile(t, executable, "test-content")
+
+			g.Expect(layer.WriteProcessExecD("web", "test-helper", executable)).To(Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "exec.d", "web", "test-helper")).To(test.HavePermissions(0755))
+		})
+
+		it("returns error for invalid exec.d process", func() {
+			g.Expect(layer.WriteProcessExecD("../web", "test-helper", filepath.Join(root, "test-executable"))).
+				To(MatchError(`invalid process type "../web"`))
 		})
 	}, spec.Report(report.Terminal{}))
 }
@@ -18,11 +18,15 @@ package layers
 
 import (
 	"fmt"
+	"net/http"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/buildpack/libbuildpack/buildplan"
 	"github.com/buildpack/libbuildpack/layers"
 	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/helper"
 	"github.com/heroku/libhkbuildpack/logger"
 )
 
@@ -30,15 +34,55 @@ import (
 type Layers struct {
 	layers.Layers
 
-	// DependencyBuildPlans contains all contributed dependencies.
+	// Credentials supplies authentication for dependency downloads.
+	Credentials Credentials
+
+	// DependencyBuildPlans contains all contributed dependencies.  Layers created by Layers add to it under a lock, so
+	// it must only be read once all contributions have completed.
 	DependencyBuildPlans buildplan.BuildPlan
 
+	// DependencyMirrors contains the mirrors consulted before downloading a dependency from its upstream URI.
+	DependencyMirrors Mirrors
+
+	// DownloadCache is the content addressed download cache shared across buildpacks.
+	DownloadCache DownloadCache
+
+	// DownloadConcurrency is the maximum number of dependencies downloaded concurrently.
+	DownloadConcurrency int
+
+	// DownloadProgress configures how the progress of dependency downloads is reported.
+	DownloadProgress Progress
+
+	// DownloadRetry configures how failed dependency downloads are retried.
+	DownloadRetry Retry
+
+	// DownloadStarted, if set, is called with the dependency of a DownloadLayer when its artifact is requested.  It may
+	// be called concurrently when downloading with DownloadAll.
+	DownloadStarted func(dependency buildpack.Dependency)
+
+	// EOLWindow is the period before a dependency's end-of-life date that a warning is logged when it is contributed.
+	EOLWindow time.Duration
+
+	// HTTPClient is the client used to download dependencies.
+	HTTPClient *http.Client
+
+	// Offline indicates that dependencies must not be downloaded over the network.
+	Offline bool
+
+	// Plan records the actions layers would take instead of taking them.  Plan mode is enabled when Plan is not nil
+	// (see BP_PLAN), and must be set before layers are created.  TouchedLayers.Plan should be set to the same Plan.
+	Plan *Plan
+
+	// Summary records the sizes of contributed layers.
+	Summary *BuildSummary
+
 	// TouchedLayers registers the layers that have been touched during this execution.
 	TouchedLayers TouchedLayers
 
-	buildpack      buildpack.Buildpack
-	buildpackCache layers.Layers
-	logger         *logger.Log
+	buildpack       buildpack.Buildpack
+	buildpackCache  layers.Layers
+	buildPlansMutex *sync.Mutex
+	logger          *logger.Log
 }
 
 // DependencyLayer returns a DependencyLayer unique to a dependency.
@@ -46,8 +90,9 @@ func (l Layers) DependencyLayer(dependency buildpack.Dependency) DependencyLayer
 	return DependencyLayer{
 		l.Layer(dependency.ID),
 		dependency,
-		l.DependencyBuildPlans,
+		buildPlans{l.DependencyBuildPlans, l.buildPlansMutex},
 		l.DownloadLayer(dependency),
+		l.EOLWindow,
 		l.logger,
 	}
 }
@@ -55,11 +100,21 @@ func (l Layers) DependencyLayer(dependency buildpack.Dependency) DependencyLayer
 // DownloadLayer returns a DownloadLayer unique to a dependency.
 func (l Layers) DownloadLayer(dependency buildpack.Dependency) DownloadLayer {
 	return DownloadLayer{
-		l.Layer(dependency.SHA256),
-		Layer{l.buildpackCache.Layer(dependency.SHA256), l.logger, l.TouchedLayers},
+		l.Layer(dependency.CacheKey()),
+		Layer{l.buildpackCache.Layer(dependency.CacheKey()), l.logger, l.Plan, l.Summary, l.TouchedLayers},
+		l.HTTPClient,
+		l.Credentials,
 		dependency,
+		l.DownloadCache,
 		l.buildpack.Info,
+		l.buildpack.Root,
+		"",
 		l.logger,
+		l.DependencyMirrors,
+		l.Offline,
+		l.DownloadProgress,
+		l.DownloadRetry,
+		l.DownloadStarted,
 	}
 }
 
@@ -69,7 +124,7 @@ func (l Layers) HelperLayer(id string, name string) HelperLayer {
 		l.Layer(id),
 		id,
 		l.buildpack,
-		l.DependencyBuildPlans,
+		buildPlans{l.DependencyBuildPlans, l.buildPlansMutex},
 		name,
 		l.logger,
 	}
@@ -77,7 +132,7 @@ func (l Layers) HelperLayer(id string, name string) HelperLayer {
 
 // Layer creates a Layer with a specified name.
 func (l Layers) Layer(name string) Layer {
-	return Layer{l.Layers.Layer(name), l.logger, l.TouchedLayers}
+	return Layer{l.Layers.Layer(name), l.logger, l.Plan, l.Summary, l.TouchedLayers}
 }
 
 // MultiDependencyLayer returns a DependencyLayer unique to a collection of dependencies.
@@ -91,14 +146,21 @@ func (l Layers) MultiDependencyLayer(name string, dependencies []buildpack.Depen
 	return MultiDependencyLayer{
 		l.Layer(name),
 		dependencies,
-		l.DependencyBuildPlans,
+		buildPlans{l.DependencyBuildPlans, l.buildPlansMutex},
 		dl,
+		l.DownloadConcurrency,
+		l.EOLWindow,
 		l.logger,
 	}
 }
 
 // WriteApplicationMetadata writes application metadata to the filesystem.
 func (l Layers) WriteApplicationMetadata(metadata Metadata) error {
+	if l.Plan != nil {
+		l.Plan.Record("app", PlanMetadata, "%d processes, %d slices", len(metadata.Processes), len(metadata.Slices))
+		return nil
+	}
+
 	if len(metadata.Slices) > 0 {
 		l.logger.FirstLine("%d application slices", len(metadata.Slices))
 	}
@@ -123,6 +185,11 @@ func (l Layers) WriteApplicationMetadata(metadata Metadata) error {
 
 // WritePersistentMetadata writes persistent metadata to the filesystem.
 func (l Layers) WritePersistentMetadata(metadata interface{}) error {
+	if l.Plan != nil {
+		l.Plan.Record("store", PlanMetadata, "persistent metadata")
+		return nil
+	}
+
 	l.logger.SubsequentLine("Writing persistent metadata")
 	return l.Layers.WritePersistentMetadata(metadata)
 }
@@ -141,12 +208,70 @@ func (l Layers) maximumTypeLength(processes Processes) int {
 
 // NewLayers creates a new instance of Layers.
 func NewLayers(layers layers.Layers, buildpackCache layers.Layers, buildpack buildpack.Buildpack, logger *logger.Log) Layers {
+	mirrors, err := DefaultMirrors()
+	if err != nil {
+		logger.Warning("Ignoring dependency mirrors: %s", err.Error())
+	}
+
+	credentials, err := DefaultCredentials()
+	if err != nil {
+		logger.Warning("Ignoring netrc credentials: %s", err.Error())
+	}
+
+	budget, err := buildpack.SizeBudget()
+	if err != nil {
+		logger.Warning("Ignoring size budget: %s", err.Error())
+	}
+
+	retention, err := DefaultRetentionPolicy()
+	if err != nil {
+		logger.Warning("Ignoring layer retention policy: %s", err.Error())
+	}
+
+	eolWindow, err := DefaultEOLWarningWindow()
+	if err != nil {
+		logger.Warning("Ignoring end-of-life warning window: %s", err.Error())
+	}
+
+	plan := DefaultPlan()
+
+	touched := NewTouchedLayers(layers.Root, logger)
+	touched.Retention = retention
+	touched.Plan = plan
+
+	client, err := defaultHTTPClient()
+	if err != nil {
+		logger.Warning("Ignoring HTTP client configuration: %s", err.Error())
+		client, _ = helper.NewHTTPClient(helper.HTTPClientOptions{Proxy: helper.EnvironmentProxy()})
+	}
+
 	return Layers{
 		Layers:               layers,
+		Credentials:          credentials,
 		DependencyBuildPlans: make(buildplan.BuildPlan),
-		TouchedLayers:        NewTouchedLayers(layers.Root, logger),
+		DependencyMirrors:    mirrors,
+		DownloadCache:        DefaultDownloadCache(),
+		DownloadConcurrency:  DefaultDownloadConcurrency,
+		DownloadProgress:     DefaultProgress(),
+		DownloadRetry:        DefaultRetry,
+		EOLWindow:            eolWindow,
+		HTTPClient:           client,
+		Offline:              DefaultOffline(),
+		Plan:                 plan,
+		Summary:              NewBuildSummary(budget, logger),
+		TouchedLayers:        touched,
 		buildpack:            buildpack,
 		buildpackCache:       buildpackCache,
+		buildPlansMutex:      &sync.Mutex{},
 		logger:               logger,
 	}
 }
+
+func defaultHTTPClient() (*http.Client, error) {
+	options, err := helper.DefaultHTTPClientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.NewHTTPClient(options)
+}
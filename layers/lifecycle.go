@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/logger"
+)
+
+const (
+	// EOLWindowEnv is the environment variable used to configure the number of days before a dependency's end-of-life
+	// date that a warning is logged.
+	EOLWindowEnv = "BP_EOL_WARNING_DAYS"
+
+	// DefaultEOLWindow is the default period before a dependency's end-of-life date that a warning is logged.
+	DefaultEOLWindow = 90 * 24 * time.Hour
+)
+
+// DefaultEOLWarningWindow returns the period before a dependency's end-of-life date that a warning is logged,
+// extracting the number of days from the BP_EOL_WARNING_DAYS environment variable.
+func DefaultEOLWarningWindow() (time.Duration, error) {
+	s, ok := os.LookupEnv(EOLWindowEnv)
+	if !ok {
+		return DefaultEOLWindow, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return DefaultEOLWindow, fmt.Errorf("invalid %s %q: must be a non-negative integer", EOLWindowEnv, s)
+	}
+
+	return time.Duration(n) * 24 * time.Hour, nil
+}
+
+// warnLifecycle logs a warning if a dependency has reached end-of-life, will reach it within the window, or is
+// deprecated.
+func warnLifecycle(logger *logger.Log, dependency buildpack.Dependency, window time.Duration, now time.Time) {
+	name := logger.PrettyIdentity(dependency)
+
+	if eol, ok := dependency.EOL(); ok {
+		if !now.Before(eol) {
+			logger.Warning("%s reached end-of-life on %s and will be removed in a future buildpack release",
+				name, dependency.EOLDate)
+			return
+		}
+
+		if eol.Sub(now) <= window {
+			logger.Warning("%s reaches end-of-life on %s (in %d days); upgrade to a supported version",
+				name, dependency.EOLDate, int(eol.Sub(now).Hours()/24)+1)
+			return
+		}
+	}
+
+	if deprecation, ok := dependency.Deprecation(); ok && !now.Before(deprecation) {
+		logger.Warning("%s was deprecated on %s", name, dependency.DeprecationDate)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestLifecycle(t *testing.T) {
+	spec.Run(t, "Lifecycle", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("uses default end-of-life window", func() {
+			g.Expect(layers.DefaultEOLWarningWindow()).To(Equal(layers.DefaultEOLWindow))
+		})
+
+		it("extracts end-of-life window from BP_EOL_WARNING_DAYS", func() {
+			defer test.ReplaceEnv(t, layers.EOLWindowEnv, "7")()
+
+			g.Expect(layers.DefaultEOLWarningWindow()).To(Equal(7 * 24 * time.Hour))
+		})
+
+		it("returns error for invalid BP_EOL_WARNING_DAYS", func() {
+			defer test.ReplaceEnv(t, layers.EOLWindowEnv, "-1")()
+
+			_, err := layers.DefaultEOLWarningWindow()
+			g.Expect(err).To(MatchError(`invalid BP_EOL_WARNING_DAYS "-1": must be a non-negative integer`))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/heroku/libhkbuildpack/helper"
+)
+
+// MetadataChange is a single difference between the previous and expected metadata of a layer.
+type MetadataChange struct {
+	// Path is the path of the field that changed as written to the metadata file (e.g. version or licenses[0].type).
+	Path string
+
+	// Previous is the value of the field in the previous metadata, or nil if the field was added.
+	Previous interface{}
+
+	// Expected is the value of the field in the expected metadata, or nil if the field was removed.
+	Expected interface{}
+}
+
+func (m MetadataChange) String() string {
+	switch {
+	case m.Previous == nil:
+		return fmt.Sprintf("%s added (%v)", m.Path, m.Expected)
+	case m.Expected == nil:
+		return fmt.Sprintf("%s removed (was %v)", m.Path, m.Previous)
+	default:
+		return fmt.Sprintf("%s changed from %v to %v", m.Path, m.Previous, m.Expected)
+	}
+}
+
+// MetadataDiff is the field-by-field difference between the previous and expected metadata of a layer, ordered by
+// path.
+type MetadataDiff []MetadataChange
+
+// MetadataDiff returns the difference between the metadata of this layer and the expected metadata.  Fields are
+// compared as they are written to the layer's metadata file, so nested fields (e.g. a dependency's licenses) are
+// reported individually.  If the layer has no metadata, every expected field is reported as added.
+func (l Layer) MetadataDiff(expected interface{}) (MetadataDiff, error) {
+	var previous map[string]interface{}
+	if err := l.ReadMetadata(&previous); err != nil {
+		return nil, err
+	}
+
+	e, err := tomlMap(expected)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff MetadataDiff
+	diffValues("", previous, e, &diff)
+	return diff, nil
+}
+
+// logMetadataDiff logs why the metadata of a layer from a previous build does not match the expected metadata.
+func (l Layer) logMetadataDiff(expected interface{}) {
+	if exists, err := helper.FileExists(l.Metadata); err != nil || !exists {
+		return
+	}
+
+	diff, err := l.MetadataDiff(expected)
+	if err != nil {
+		l.Logger.Debug("Unable to compare layer metadata: %s", err.Error())
+		return
+	}
+
+	if len(diff) == 0 {
+		return
+	}
+
+	l.Logger.Info("Layer %s changed since previous build:", filepath.Base(l.Root))
+	for _, c := range diff {
+		l.Logger.SubsequentLine("%s", c)
+	}
+}
+
+func diffValues(path string, previous interface{}, expected interface{}, diff *MetadataDiff) {
+	p, pOk := previous.(map[string]interface{})
+	e, eOk := expected.(map[string]interface{})
+	if pOk && eOk {
+		for _, k := range unionKeys(p, e) {
+			diffValues(join(path, k), lookup(p, k), lookup(e, k), diff)
+		}
+		return
+	}
+
+	pv, ev := reflect.ValueOf(previous), reflect.ValueOf(expected)
+	if pv.Kind() == reflect.Slice && ev.Kind() == reflect.Slice {
+		for i := 0; i < pv.Len() || i < ev.Len(); i++ {
+			var pi, ei interface{}
+			if i < pv.Len() {
+				pi = pv.Index(i).Interface()
+			}
+			if i < ev.Len() {
+				ei = ev.Index(i).Interface()
+			}
+
+			diffValues(fmt.Sprintf("%s[%d]", path, i), pi, ei, diff)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(previous, expected) {
+		*diff = append(*diff, MetadataChange{path, previous, expected})
+	}
+}
+
+func join(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return strings.Join([]string{path, key}, ".")
+}
+
+// tomlMap returns the generic representation of a value as it is written to a TOML file.
+func tomlMap(v interface{}) (map[string]interface{}, error) {
+	b := &bytes.Buffer{}
+	if err := toml.NewEncoder(b).Encode(v); err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if _, err := toml.Decode(b.String(), &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// lookup returns the value of a key, matched case-insensitively as it is when decoding TOML.
+func lookup(m map[string]interface{}, key string) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// unionKeys returns the sorted keys of both maps, matched case-insensitively and preferring the spelling in b.
+func unionKeys(a map[string]interface{}, b map[string]interface{}) []string {
+	keys := make(map[string]string)
+	for k := range a {
+		keys[strings.ToLower(k)] = k
+	}
+	for k := range b {
+		keys[strings.ToLower(k)] = k
+	}
+
+	var sorted []string
+	for _, k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i int, j int) bool {
+		return strings.ToLower(sorted[i]) < strings.ToLower(sorted[j])
+	})
+
+	return sorted
+}
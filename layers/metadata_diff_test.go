@@ -0,0 +1,124 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"bytes"
+	"testing"
+
+	bp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestMetadataDiff(t *testing.T) {
+	spec.Run(t, "MetadataDiff", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			info  *bytes.Buffer
+			layer layers.Layer
+		)
+
+		it.Before(func() {
+			info = &bytes.Buffer{}
+			layer = layers.NewLayers(bp.Layers{Root: test.ScratchDir(t, "metadata-diff")}, bp.Layers{}, buildpack.Buildpack{}, logger.NewFromWriters(nil, info)).Layer("test-layer")
+		})
+
+		it("reports changed fields", func() {
+			test.WriteFile(t, layer.Metadata, `[metadata]
+Alpha = "test-value"
+Bravo = 1
+`)
+
+			g.Expect(layer.MetadataDiff(metadata{"test-value", 2})).To(Equal(layers.MetadataDiff{
+				{Path: "Bravo", Previous: int64(1), Expected: int64(2)},
+			}))
+		})
+
+		it("reports added and removed fields", func() {
+			test.WriteFile(t, layer.Metadata, `[metadata]
+Alpha = "test-value"
+Charlie = "test-charlie"
+`)
+
+			g.Expect(layer.MetadataDiff(metadata{"test-value", 1})).To(Equal(layers.MetadataDiff{
+				{Path: "Bravo", Expected: int64(1)},
+				{Path: "Charlie", Previous: "test-charlie"},
+			}))
+		})
+
+		it("reports nested dependency fields", func() {
+			test.WriteFile(t, layer.Metadata, `[metadata]
+ID = "test-id"
+Name = ""
+Version = "1.0"
+URI = "test-uri"
+SHA256 = "test-sha256"
+Stacks = ["test-stack"]
+
+[[metadata.Licenses]]
+Type = "MIT"
+URI = ""
+`)
+
+			d := buildpack.Dependency{
+				ID:       "test-id",
+				Version:  internal.NewTestVersion(t, "1.1"),
+				URI:      "test-uri",
+				SHA256:   "test-sha256",
+				Stacks:   buildpack.Stacks{"test-stack"},
+				Licenses: buildpack.Licenses{{Type: "Apache-2.0"}},
+			}
+
+			g.Expect(layer.MetadataDiff(d)).To(Equal(layers.MetadataDiff{
+				{Path: "licenses[0].type", Previous: "MIT", Expected: "Apache-2.0"},
+				{Path: "version", Previous: "1.0", Expected: "1.1"},
+			}))
+		})
+
+		it("reports all fields as added without previous metadata", func() {
+			g.Expect(layer.MetadataDiff(metadata{"test-value", 1})).To(Equal(layers.MetadataDiff{
+				{Path: "Alpha", Expected: "test-value"},
+				{Path: "Bravo", Expected: int64(1)},
+			}))
+		})
+
+		it("logs why a layer is rebuilt", func() {
+			test.WriteFile(t, layer.Metadata, `[metadata]
+Alpha = "test-value"
+Bravo = 1
+`)
+
+			g.Expect(layer.MetadataMatches(metadata{"test-value", 2})).To(BeFalse())
+			g.Expect(info.String()).To(ContainSubstring("Layer test-layer changed since previous build:"))
+			g.Expect(info.String()).To(ContainSubstring("Bravo changed from 1 to 2"))
+		})
+
+		it("does not log without previous metadata", func() {
+			g.Expect(layer.MetadataMatches(metadata{"test-value", 2})).To(BeFalse())
+			g.Expect(info.String()).To(BeEmpty())
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DependencyMirrorsEnv is the environment variable used to configure dependency mirrors.  The value is a comma or
+// newline separated list of <upstream>=<mirror> pairs where upstream is either a host (github.com) or a URI prefix
+// (https://github.com/example/).
+const DependencyMirrorsEnv = "BP_DEPENDENCY_MIRRORS"
+
+// Mirrors is a mapping of upstream hosts or URI prefixes to the mirrors that serve identical artifacts.
+type Mirrors map[string]string
+
+// DefaultMirrors creates a new instance of Mirrors, extracting the value from the BP_DEPENDENCY_MIRRORS environment
+// variable.
+func DefaultMirrors() (Mirrors, error) {
+	return ParseMirrors(os.Getenv(DependencyMirrorsEnv))
+}
+
+// ParseMirrors parses a comma or newline separated list of <upstream>=<mirror> pairs.
+func ParseMirrors(value string) (Mirrors, error) {
+	m := make(Mirrors)
+
+	for _, entry := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid dependency mirror %q: must be <upstream>=<mirror>", entry)
+		}
+
+		m[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return m, nil
+}
+
+// Rewrite returns the mirrored location of a URI and true if a mirror is configured for it.  URI prefixes take
+// precedence over hosts and the longest matching prefix wins.
+func (m Mirrors) Rewrite(uri string) (string, bool) {
+	prefix := ""
+	for k := range m {
+		if strings.Contains(k, "://") && strings.HasPrefix(uri, k) && len(k) > len(prefix) {
+			prefix = k
+		}
+	}
+
+	if prefix != "" {
+		return joinMirror(m[prefix], strings.TrimPrefix(uri, prefix)), true
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	mirror, ok := m[u.Host]
+	if !ok {
+		return "", false
+	}
+
+	origin := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	return joinMirror(mirror, strings.TrimPrefix(uri, origin)), true
+}
+
+// joinMirror joins a mirror and the remainder of a URI with exactly one slash, regardless of whether either has one.
+func joinMirror(mirror string, remainder string) string {
+	if remainder == "" {
+		return mirror
+	}
+
+	return strings.TrimSuffix(mirror, "/") + "/" + strings.TrimPrefix(remainder, "/")
+}
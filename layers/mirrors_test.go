@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestMirrors(t *testing.T) {
+	spec.Run(t, "Mirrors", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("parses mirrors from the environment", func() {
+			defer test.ReplaceEnv(t, layers.DependencyMirrorsEnv, "github.com=https://mirror.example.com/github,\nhttps://example.com/a/ = https://mirror.example.com/a/")()
+
+			g.Expect(layers.DefaultMirrors()).To(Equal(layers.Mirrors{
+				"github.com":             "https://mirror.example.com/github",
+				"https://example.com/a/": "https://mirror.example.com/a/",
+			}))
+		})
+
+		it("returns empty mirrors when unset", func() {
+			defer test.ReplaceEnv(t, layers.DependencyMirrorsEnv, "")()
+
+			g.Expect(layers.DefaultMirrors()).To(BeEmpty())
+		})
+
+		it("rejects malformed mirrors", func() {
+			_, err := layers.ParseMirrors("github.com")
+			g.Expect(err).To(MatchError(`invalid dependency mirror "github.com": must be <upstream>=<mirror>`))
+		})
+
+		it("rewrites by host", func() {
+			m := layers.Mirrors{"github.com": "https://mirror.example.com/github/"}
+
+			uri, ok := m.Rewrite("https://github.com/example/test.tgz")
+			g.Expect(ok).To(BeTrue())
+			g.Expect(uri).To(Equal("https://mirror.example.com/github/example/test.tgz"))
+		})
+
+		it("rewrites by longest prefix", func() {
+			m := layers.Mirrors{
+				"github.com":                  "https://host-mirror.example.com",
+				"https://github.com/":         "https://short-mirror.example.com/",
+				"https://github.com/example/": "https://long-mirror.example.com/",
+			}
+
+			uri, _ := m.Rewrite("https://github.com/example/test.tgz")
+			g.Expect(uri).To(Equal("https://long-mirror.example.com/test.tgz"))
+
+			uri, _ = m.Rewrite("https://github.com/other/test.tgz")
+			g.Expect(uri).To(Equal("https://short-mirror.example.com/other/test.tgz"))
+		})
+
+		it("joins host mirrors with and without trailing slashes", func() {
+			for _, mirror := range []string{"https://mirror.example.com/github", "https://mirror.example.com/github/"} {
+				uri, _ := layers.Mirrors{"github.com": mirror}.Rewrite("https://github.com/example/test.tgz")
+				g.Expect(uri).To(Equal("https://mirror.example.com/github/example/test.tgz"))
+			}
+		})
+
+		it("joins prefix mirrors with and without trailing slashes", func() {
+			for _, prefix := range []string{"https://github.com/example", "https://github.com/example/"} {
+				for _, mirror := range []string{"https://mirror.example.com/example", "https://mirror.example.com/example/"} {
+					uri, _ := layers.Mirrors{prefix: mirror}.Rewrite("https://github.com/example/test.tgz")
+					g.Expect(uri).To(Equal("https://mirror.example.com/example/test.tgz"))
+				}
+			}
+		})
+
+		it("does not rewrite unmatched URIs", func() {
+			m := layers.Mirrors{"github.com": "https://mirror.example.com"}
+
+			_, ok := m.Rewrite("https://example.com/test.tgz")
+			g.Expect(ok).To(BeFalse())
+		})
+	}, spec.Report(report.Terminal{}))
+}
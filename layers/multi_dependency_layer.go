@@ -20,10 +20,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
-	"github.com/buildpack/libbuildpack/buildplan"
 	"github.com/heroku/libhkbuildpack/buildpack"
 	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/sbom"
 )
 
 type MultiDependencyLayer struct {
@@ -32,8 +33,10 @@ type MultiDependencyLayer struct {
 	// Dependencies are the dependencies provided by this layer.
 	Dependencies []buildpack.Dependency
 
-	dependencyBuildPlans buildplan.BuildPlan
+	dependencyBuildPlans buildPlans
 	downloadLayers       []DownloadLayer
+	downloadConcurrency  int
+	eolWindow            time.Duration
 	logger               *logger.Log
 }
 
@@ -42,29 +45,46 @@ type MultiDependencyLayerContributor func(artifact string, layer MultiDependency
 
 // Contribute facilitates custom contribution of an artifacts to a layer.  If the artifacts have already been
 // contributed, the contribution is validated and the contributor is not called.  If the contribution is out of date,
-// the layer is completely removed before contribution occurs.
+// the layer is completely removed before contribution occurs.  Artifacts are downloaded concurrently and contributors
+// are then called in the order of the dependencies.  A warning is logged for each dependency that is deprecated or
+// within the end-of-life window.  A bill-of-materials for the dependencies is written alongside the layer metadata.  In
+// plan mode, the contribution and any required downloads are recorded instead.
 func (l MultiDependencyLayer) Contribute(contributors map[string]MultiDependencyLayerContributor, flags ...Flag) error {
 	for _, dl := range l.downloadLayers {
 		dl.Touch()
 	}
 
+	for _, d := range l.Dependencies {
+		warnLifecycle(l.logger, d, l.eolWindow, time.Now())
+	}
+
+	if l.planning() {
+		if err := l.planContribution(flags...); err != nil {
+			return err
+		}
+
+		l.contributeToBuildPlan()
+		return nil
+	}
+
 	if err := l.Layer.Contribute(multiDependency{l.Dependencies}, func(layer Layer) error {
 		if err := os.RemoveAll(l.Root); err != nil {
 			return err
 		}
 
-		for i, d := range l.Dependencies {
-			c, ok := contributors[d.ID]
-			if !ok {
+		for _, d := range l.Dependencies {
+			if _, ok := contributors[d.ID]; !ok {
 				return fmt.Errorf("no contributor found for dependency %s", d.ID)
 			}
+		}
 
-			a, err := l.downloadLayers[i].Artifact()
-			if err != nil {
-				return err
-			}
+		artifacts, err := DownloadAll(l.downloadLayers, l.downloadConcurrency)
+		if err != nil {
+			return err
+		}
 
-			if err := c(a, l); err != nil {
+		for i, d := range l.Dependencies {
+			if err := contributors[d.ID](artifacts[i], l); err != nil {
 				return err
 			}
 		}
@@ -75,24 +95,47 @@ func (l MultiDependencyLayer) Contribute(contributors map[string]MultiDependency
 	}
 
 	l.contributeToBuildPlan()
-	return nil
+
+	var components []sbom.Component
+	for _, d := range l.Dependencies {
+		components = append(components, sbom.NewComponent(d))
+	}
+
+	return l.WriteSBOM(components...)
 }
 
 func (l *MultiDependencyLayer) contributeToBuildPlan() {
 	for _, d := range l.Dependencies {
 		l.Logger.Debug("Contributing %s to bill-of-materials", d.ID)
 
-		l.dependencyBuildPlans[d.ID] = buildplan.Dependency{
-			Version: d.Version.Original(),
-			Metadata: buildplan.Metadata{
-				"name":     d.Name,
-				"uri":      d.URI,
-				"sha256":   d.SHA256,
-				"stacks":   d.Stacks,
-				"licenses": d.Licenses,
-			},
+		l.dependencyBuildPlans.add(d.ID, dependencyBuildPlan(d))
+	}
+}
+
+// planContribution records the contribution of the layer and the downloads it would require in plan mode.
+func (l MultiDependencyLayer) planContribution(flags ...Flag) error {
+	expected := multiDependency{l.Dependencies}
+
+	matches, err := l.MetadataMatches(expected)
+	if err != nil {
+		return err
+	}
+
+	if err := l.contribute(expected, expected, matches, nil, flags...); err != nil {
+		return err
+	}
+
+	if matches {
+		return nil
+	}
+
+	for _, dl := range l.downloadLayers {
+		if _, err := dl.Artifact(); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
 
 type multiDependency struct {
@@ -173,6 +173,31 @@ URI = "%s"`,
 			}))
 		})
 
+		it("contributes identifiers to build plan", func() {
+			dependencies[0].PURL = "pkg:generic/test-name@1.0"
+			layer = ls.MultiDependencyLayer("test-name", dependencies)
+			test.WriteFile(t, layer.Metadata, `[metadata]
+[[metadata.dependencies]]
+ID = "%s"
+Version = "%s"
+SHA256 = "%s"
+URI = "%s"
+PURL = "%s"
+
+[[metadata.dependencies]]
+ID = "%s"
+Version = "%s"
+SHA256 = "%s"
+URI = "%s"`,
+				dependencies[0].ID, dependencies[0].Version.Original(), dependencies[0].SHA256, dependencies[0].URI, dependencies[0].PURL,
+				dependencies[1].ID, dependencies[1].Version.Original(), dependencies[1].SHA256, dependencies[1].URI)
+
+			g.Expect(layer.Contribute(map[string]layers.MultiDependencyLayerContributor{})).To(Succeed())
+
+			g.Expect(ls.DependencyBuildPlans[dependencies[0].ID].Metadata).To(HaveKeyWithValue("purl", "pkg:generic/test-name@1.0"))
+			g.Expect(ls.DependencyBuildPlans[dependencies[1].ID].Metadata).NotTo(HaveKey("purl"))
+		})
+
 		it("cleans layer when contributing dependency layer", func() {
 			test.WriteFile(t, filepath.Join(root, fmt.Sprintf("%s.toml", dependencies[0].SHA256)), `[metadata]
 ID = "%s"
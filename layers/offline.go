@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+)
+
+// OfflineEnv is the environment variable used to enable offline mode.  When enabled, dependencies must be served from
+// the buildpack dependency cache or a previous build and are never downloaded over the network.
+const OfflineEnv = "BP_OFFLINE"
+
+// OfflineError is returned when a dependency must be downloaded but offline mode is enabled.  The message names the
+// dependency's id, version, and strongest checksum.
+type OfflineError struct {
+	// Dependency is the dependency that is not cached.
+	Dependency buildpack.Dependency
+}
+
+func (e OfflineError) Error() string {
+	version := ""
+	if e.Dependency.Version.Version != nil {
+		version = e.Dependency.Version.Original()
+	}
+
+	c, _ := e.Dependency.StrongestChecksum()
+	return fmt.Sprintf("dependency %s %s (%s %s) is not cached and offline mode is enabled",
+		e.Dependency.ID, version, c.Algorithm(), c.Hex())
+}
+
+// DefaultOffline returns whether offline mode is enabled by the BP_OFFLINE environment variable.
+func DefaultOffline() bool {
+	offline, err := strconv.ParseBool(os.Getenv(OfflineEnv))
+	return err == nil && offline
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestOffline(t *testing.T) {
+	spec.Run(t, "Offline", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("is enabled by the environment", func() {
+			defer test.ReplaceEnv(t, layers.OfflineEnv, "true")()
+
+			g.Expect(layers.DefaultOffline()).To(BeTrue())
+		})
+
+		it("is disabled by default", func() {
+			defer test.ReplaceEnv(t, layers.OfflineEnv, "")()
+
+			g.Expect(layers.DefaultOffline()).To(BeFalse())
+		})
+	}, spec.Report(report.Terminal{}))
+}
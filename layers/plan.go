@@ -0,0 +1,111 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/heroku/libhkbuildpack/logger"
+)
+
+// PlanEnv is the environment variable used to enable plan mode.  In plan mode, the actions that layers would take are
+// recorded and reported instead of mutating the layers directory.
+const PlanEnv = "BP_PLAN"
+
+const (
+	// PlanContribute indicates that a layer would be contributed.
+	PlanContribute = "contribute"
+
+	// PlanDownload indicates that a dependency would be downloaded.
+	PlanDownload = "download"
+
+	// PlanEnvironment indicates that an environment variable, profile.d script, or exec.d executable would be written.
+	PlanEnvironment = "environment"
+
+	// PlanMetadata indicates that application or persistent metadata would be written.
+	PlanMetadata = "metadata"
+
+	// PlanRemove indicates that an unused layer would be removed.
+	PlanRemove = "remove"
+
+	// PlanReuse indicates that a cached layer or download would be reused.
+	PlanReuse = "reuse"
+)
+
+// PlannedAction is an action that would have been taken on a layer.
+type PlannedAction struct {
+	// Layer is the name of the layer.
+	Layer string
+
+	// Action is the type of the action (e.g. contribute or download).
+	Action string
+
+	// Detail describes the action.
+	Detail string
+}
+
+func (p PlannedAction) String() string {
+	return fmt.Sprintf("%s: %s %s", p.Layer, p.Action, p.Detail)
+}
+
+// Plan records the actions that would be taken on layers in plan mode.  It is safe to use concurrently.
+type Plan struct {
+	actions []PlannedAction
+	mutex   sync.Mutex
+}
+
+// Actions returns the recorded actions in the order they were recorded.
+func (p *Plan) Actions() []PlannedAction {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]PlannedAction(nil), p.actions...)
+}
+
+// Record records an action that would be taken on a layer.
+func (p *Plan) Record(layer string, action string, format string, args ...interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.actions = append(p.actions, PlannedAction{layer, action, fmt.Sprintf(format, args...)})
+}
+
+// Report logs the recorded actions.
+func (p *Plan) Report(logger *logger.Log) {
+	logger.FirstLine("Plan (no changes were made):")
+
+	actions := p.Actions()
+	if len(actions) == 0 {
+		logger.SubsequentLine("No actions")
+	}
+
+	for _, a := range actions {
+		logger.SubsequentLine("%s", a)
+	}
+}
+
+// DefaultPlan returns a new Plan if plan mode is enabled by the BP_PLAN environment variable, and nil otherwise.
+func DefaultPlan() *Plan {
+	if plan, err := strconv.ParseBool(os.Getenv(PlanEnv)); err == nil && plan {
+		return &Plan{}
+	}
+
+	return nil
+}
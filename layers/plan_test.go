@@ -0,0 +1,192 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	layersBp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestPlan(t *testing.T) {
+	spec.Run(t, "Plan", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			root string
+			ls   layers.Layers
+		)
+
+		it.Before(func() {
+			root = test.ScratchDir(t, "plan")
+
+			defer test.ReplaceEnv(t, layers.PlanEnv, "true")()
+			ls = layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")}, buildpack.Buildpack{}, &logger.Log{})
+		})
+
+		it("is disabled by default", func() {
+			g.Expect(layers.DefaultPlan()).To(BeNil())
+		})
+
+		it("is enabled by BP_PLAN", func() {
+			g.Expect(ls.Plan).NotTo(BeNil())
+			g.Expect(ls.TouchedLayers.Plan).To(BeIdenticalTo(ls.Plan))
+		})
+
+		it("records contribution without calling contributor", func() {
+			layer := ls.Layer("test-layer")
+
+			contributed := false
+			g.Expect(layer.Contribute(metadata{"test-value", 1}, func(layer layers.Layer) error {
+				contributed = true
+				return nil
+			})).To(Succeed())
+
+			g.Expect(contributed).To(BeFalse())
+			g.Expect(layer.Metadata).NotTo(BeAnExistingFile())
+			g.Expect(ls.Plan.Actions()).To(ConsistOf(layers.PlannedAction{Layer: "test-layer", Action: layers.PlanContribute, Detail: "test-value - 1"}))
+		})
+
+		it("records environment without writing it", func() {
+			layer := ls.Layer("test-layer")
+
+			g.Expect(layer.OverrideLaunchEnv("TEST_KEY", "test-value")).To(Succeed())
+			g.Expect(layer.WriteProfile("test-profile", "test-value")).To(Succeed())
+
+			g.Expect(layer.Root).NotTo(BeAnExistingFile())
+			g.Expect(ls.Plan.Actions()).To(HaveLen(2))
+			g.Expect(ls.Plan.Actions()[0]).To(Equal(layers.PlannedAction{Layer: "test-layer", Action: layers.PlanEnvironment, Detail: "TEST_KEY to launch"}))
+		})
+
+		it("records environment helpers without writing them", func() {
+			layer := ls.Layer("test-layer")
+
+			g.Expect(layer.WriteEnv(layers.ProcessScope("web"), layers.EnvOverride, "TEST_KEY", "test-value")).To(Succeed())
+			g.Expect(layer.WriteEnvMap(layers.BuildScope, layers.EnvDefault, map[string]string{"TEST_KEY": "test-value"})).To(Succeed())
+			g.Expect(layer.DefaultEnv(layers.SharedScope, "TEST_KEY", "test-value")).To(Succeed())
+			g.Expect(layer.PrependPathEnv(layers.LaunchScope, "PATH", "test-value")).To(Succeed())
+
+			g.Expect(layer.Root).NotTo(BeAnExistingFile())
+			g.Expect(ls.Plan.Actions()).To(Equal([]layers.PlannedAction{
+				{Layer: "test-layer", Action: layers.PlanEnvironment, Detail: "TEST_KEY to launch (web)"},
+				{Layer: "test-layer", Action: layers.PlanEnvironment, Detail: "TEST_KEY to build"},
+				{Layer: "test-layer", Action: layers.PlanEnvironment, Detail: "TEST_KEY to shared"},
+				{Layer: "test-layer", Action: layers.PlanEnvironment, Detail: "PATH to launch"},
+			}))
+		})
+
+		it("rejects invalid environment scopes", func() {
+			layer := ls.Layer("test-layer")
+
+			g.Expect(layer.WriteEnv(layers.Scope("invalid"), layers.EnvOverride, "TEST_KEY", "test-value")).
+				To(MatchError(`invalid environment scope "invalid"`))
+			g.Expect(layer.PrependPathEnv(layers.ProcessScope("web/api"), "PATH", "test-value")).
+				To(MatchError(`invalid environment scope "env.launch/web/api"`))
+			g.Expect(ls.Plan.Actions()).To(BeEmpty())
+		})
+
+		it("records download for dependency layer", func() {
+			dependency := buildpack.Dependency{
+				ID:      "test-id",
+				Name:    "test-name",
+				Version: internal.NewTestVersion(t, "1.0"),
+				SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+				URI:     "https://test.com/test-path",
+			}
+
+			g.Expect(ls.DependencyLayer(dependency).Contribute(func(artifact string, layer layers.DependencyLayer) error {
+				t.Fatal("contributor must not be called")
+				return nil
+			})).To(Succeed())
+
+			actions := ls.Plan.Actions()
+			g.Expect(actions).To(HaveLen(2))
+			g.Expect(actions[0].Layer).To(Equal("test-id"))
+			g.Expect(actions[0].Action).To(Equal(layers.PlanContribute))
+			g.Expect(actions[1].Layer).To(Equal(dependency.SHA256))
+			g.Expect(actions[1].Action).To(Equal(layers.PlanDownload))
+			g.Expect(actions[1].Detail).To(HaveSuffix("from https://test.com/test-path"))
+			g.Expect(filepath.Join(root, dependency.SHA256)).NotTo(BeAnExistingFile())
+			g.Expect(ls.DependencyBuildPlans).To(HaveKey("test-id"))
+		})
+
+		it("records reuse for cached download", func() {
+			dependency := buildpack.Dependency{
+				ID:      "test-id",
+				Version: internal.NewTestVersion(t, "1.0"),
+				SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+				URI:     "https://test.com/test-path",
+			}
+
+			layer := ls.DownloadLayer(dependency)
+			test.WriteFile(t, layer.Metadata, `[metadata]
+ID = "%s"
+Version = "%s"
+SHA256 = "%s"
+URI = "%s"`, dependency.ID, dependency.Version.Original(), dependency.SHA256, dependency.URI)
+
+			g.Expect(layer.Artifact()).To(Equal(filepath.Join(layer.Root, "test-path")))
+
+			actions := ls.Plan.Actions()
+			g.Expect(actions).To(HaveLen(1))
+			g.Expect(actions[0].Layer).To(Equal(dependency.SHA256))
+			g.Expect(actions[0].Action).To(Equal(layers.PlanReuse))
+			g.Expect(actions[0].Detail).To(HaveSuffix("cached download from previous build"))
+		})
+
+		it("records reuse for cached layer", func() {
+			layer := ls.Layer("test-layer")
+			test.WriteFile(t, layer.Metadata, `[metadata]
+Alpha = "test-value"
+Bravo = 1`)
+
+			g.Expect(layer.Contribute(metadata{"test-value", 1}, func(layer layers.Layer) error {
+				return nil
+			})).To(Succeed())
+
+			g.Expect(ls.Plan.Actions()).To(ConsistOf(layers.PlannedAction{Layer: "test-layer", Action: layers.PlanReuse, Detail: "test-value - 1"}))
+		})
+
+		it("records removal of unused layers without removing them", func() {
+			test.WriteFile(t, filepath.Join(root, "unused.toml"), "")
+			test.TouchFile(t, root, "unused", "test-file")
+
+			g.Expect(ls.TouchedLayers.Cleanup()).To(Succeed())
+
+			g.Expect(filepath.Join(root, "unused.toml")).To(BeAnExistingFile())
+			g.Expect(filepath.Join(root, layers.LedgerLayer+".toml")).NotTo(BeAnExistingFile())
+			g.Expect(ls.Plan.Actions()).To(ConsistOf(layers.PlannedAction{Layer: "unused", Action: layers.PlanRemove, Detail: "unused layer"}))
+		})
+
+		it("records metadata without writing it", func() {
+			g.Expect(ls.WriteApplicationMetadata(layers.Metadata{})).To(Succeed())
+
+			g.Expect(filepath.Join(root, "launch.toml")).NotTo(BeAnExistingFile())
+			g.Expect(ls.Plan.Actions()).To(ConsistOf(layers.PlannedAction{Layer: "app", Action: layers.PlanMetadata, Detail: "0 processes, 0 slices"}))
+		})
+	}, spec.Report(report.Terminal{}))
+}
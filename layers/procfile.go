@@ -0,0 +1,132 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// WebProcess is the process type selected as the default process when present.
+const WebProcess = "web"
+
+// ReadProcfile reads the process types declared in a Procfile.  If the file does not exist, no processes are returned.
+func ReadProcfile(file string) (Processes, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := ParseProcfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %s", file, err.Error())
+	}
+
+	return p, nil
+}
+
+// ParseProcfile parses process types in the Procfile format, one "<type>: <command>" per line.  Blank lines and lines
+// starting with # are ignored.  Process types may only contain letters, numbers, underscores, and hyphens, and may
+// only be declared once.
+func ParseProcfile(in io.Reader) (Processes, error) {
+	var processes Processes
+	lines := make(map[string]int)
+
+	s := bufio.NewScanner(in)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: must be <type>: <command>", n)
+		}
+
+		t, command := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+
+		if !processType.MatchString(t) {
+			return nil, fmt.Errorf("line %d: invalid process type %q", n, t)
+		}
+
+		if command == "" {
+			return nil, fmt.Errorf("line %d: process type %s has no command", n, t)
+		}
+
+		if previous, ok := lines[t]; ok {
+			return nil, fmt.Errorf("line %d: process type %s is already declared on line %d", n, t, previous)
+		}
+		lines[t] = n
+
+		processes = append(processes, Process{Type: t, Command: command})
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return processes, nil
+}
+
+// MergeProcesses merges user declared processes (e.g. from a Procfile) with processes provided by a buildpack.  When
+// both declare a process type, the user's command wins.  The result is ordered by type.
+func MergeProcesses(defaults Processes, user Processes) Processes {
+	merged := make(map[string]Process)
+
+	for _, p := range defaults {
+		merged[p.Type] = p
+	}
+
+	for _, p := range user {
+		merged[p.Type] = p
+	}
+
+	var processes Processes
+	for _, p := range merged {
+		processes = append(processes, p)
+	}
+
+	sort.Slice(processes, func(i int, j int) bool {
+		return processes[i].Type < processes[j].Type
+	})
+
+	return processes
+}
+
+// DefaultProcess returns the process that should run by default: the web process if one is declared, otherwise the
+// only process if exactly one is declared.
+func DefaultProcess(processes Processes) (Process, bool) {
+	for _, p := range processes {
+		if p.Type == WebProcess {
+			return p, true
+		}
+	}
+
+	if len(processes) == 1 {
+		return processes[0], true
+	}
+
+	return Process{}, false
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestProcfile(t *testing.T) {
+	spec.Run(t, "Procfile", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		when("ParseProcfile", func() {
+
+			it("parses processes", func() {
+				g.Expect(layers.ParseProcfile(strings.NewReader(`# test comment
+web: bin/server --port $PORT
+
+worker:bundle exec sidekiq -q default:1
+  release :  bin/migrate  
+`))).To(Equal(layers.Processes{
+					{Type: "web", Command: "bin/server --port $PORT"},
+					{Type: "worker", Command: "bundle exec sidekiq -q default:1"},
+					{Type: "release", Command: "bin/migrate"},
+				}))
+			})
+
+			it("returns error for duplicate process type", func() {
+				_, err := layers.ParseProcfile(strings.NewReader("web: test-1\nweb: test-2\n"))
+				g.Expect(err).To(MatchError("line 2: process type web is already declared on line 1"))
+			})
+
+			it("returns error for invalid process type", func() {
+				_, err := layers.ParseProcfile(strings.NewReader("test web: test-command\n"))
+				g.Expect(err).To(MatchError(`line 1: invalid process type "test web"`))
+			})
+
+			it("returns error for missing command", func() {
+				_, err := layers.ParseProcfile(strings.NewReader("web:\n"))
+				g.Expect(err).To(MatchError("line 1: process type web has no command"))
+			})
+
+			it("returns error for malformed line", func() {
+				_, err := layers.ParseProcfile(strings.NewReader("test-command\n"))
+				g.Expect(err).To(MatchError("line 1: must be <type>: <command>"))
+			})
+		})
+
+		when("ReadProcfile", func() {
+
+			it("reads Procfile", func() {
+				root := test.ScratchDir(t, "procfile")
+				test.WriteFile(t, filepath.Join(root, "Procfile"), "web: test-command")
+
+				g.Expect(layers.ReadProcfile(filepath.Join(root, "Procfile"))).To(Equal(layers.Processes{
+					{Type: "web", Command: "test-command"},
+				}))
+			})
+
+			it("returns no processes without Procfile", func() {
+				g.Expect(layers.ReadProcfile(filepath.Join(test.ScratchDir(t, "procfile"), "Procfile"))).To(BeNil())
+			})
+		})
+
+		it("merges processes with user processes winning", func() {
+			g.Expect(layers.MergeProcesses(
+				layers.Processes{{Type: "web", Command: "default-web"}, {Type: "worker", Command: "default-worker"}},
+				layers.Processes{{Type: "web", Command: "user-web"}, {Type: "console", Command: "user-console"}},
+			)).To(Equal(layers.Processes{
+				{Type: "console", Command: "user-console"},
+				{Type: "web", Command: "user-web"},
+				{Type: "worker", Command: "default-worker"},
+			}))
+		})
+
+		when("DefaultProcess", func() {
+
+			it("selects web process", func() {
+				p, ok := layers.DefaultProcess(layers.Processes{{Type: "worker", Command: "test-worker"}, {Type: "web", Command: "test-web"}})
+				g.Expect(ok).To(BeTrue())
+				g.Expect(p).To(Equal(layers.Process{Type: "web", Command: "test-web"}))
+			})
+
+			it("selects only process", func() {
+				p, ok := layers.DefaultProcess(layers.Processes{{Type: "worker", Command: "test-worker"}})
+				g.Expect(ok).To(BeTrue())
+				g.Expect(p.Type).To(Equal("worker"))
+			})
+
+			it("does not select among multiple processes without web", func() {
+				_, ok := layers.DefaultProcess(layers.Processes{{Type: "worker", Command: "test-worker"}, {Type: "clock", Command: "test-clock"}})
+				g.Expect(ok).To(BeFalse())
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Progress configures how the progress of a download is reported.
+type Progress struct {
+	// Interval is the minimum time between progress reports.  A zero value disables progress reporting.
+	Interval time.Duration
+}
+
+// DefaultProgress returns the Progress used by Layers unless otherwise configured.  When the console is a terminal,
+// progress is reported every few seconds.  Otherwise, such as in build logs, progress is reported at coarse intervals.
+func DefaultProgress() Progress {
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		return Progress{Interval: 2 * time.Second}
+	}
+
+	return Progress{Interval: 30 * time.Second}
+}
+
+// reader wraps source so that progress is reported to log while it is read.  offset is the number of bytes already
+// transferred (e.g. when resuming) and total is the expected total size or -1 if unknown.  Progress is reported from a
+// separate goroutine every interval; the returned function stops it and reports the final progress, and must be called
+// once reading has finished.
+func (p Progress) reader(source io.Reader, offset int64, total int64, log func(string, ...interface{})) (io.Reader, func()) {
+	if p.Interval <= 0 {
+		return source, func() {}
+	}
+
+	r := &progressReader{
+		current: offset,
+		source:  source,
+		log:     log,
+		start:   time.Now(),
+		offset:  offset,
+		total:   total,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go r.run(time.NewTicker(p.Interval))
+	return r, r.stop
+}
+
+type progressReader struct {
+	current int64 // accessed atomically, first for 64-bit alignment
+	source  io.Reader
+	log     func(string, ...interface{})
+	start   time.Time
+	offset  int64
+	total   int64
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.source.Read(b)
+	atomic.AddInt64(&p.current, int64(n))
+	return n, err
+}
+
+func (p *progressReader) run(ticker *time.Ticker) {
+	defer close(p.stopped)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			p.report(now)
+		}
+	}
+}
+
+func (p *progressReader) stop() {
+	close(p.done)
+	<-p.stopped
+	p.report(time.Now())
+}
+
+func (p *progressReader) report(now time.Time) {
+	elapsed := now.Sub(p.start).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	current := atomic.LoadInt64(&p.current)
+	rate := float64(current-p.offset) / elapsed
+
+	if p.total <= 0 {
+		p.log("Downloaded %s at %s/s", formatBytes(float64(current)), formatBytes(rate))
+		return
+	}
+
+	eta := "unknown"
+	if rate > 0 {
+		eta = time.Duration(float64(p.total-current) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+
+	p.log("Downloaded %s of %s (%d%%) at %s/s, ETA %s", formatBytes(float64(current)), formatBytes(float64(p.total)),
+		current*100/p.total, formatBytes(rate), eta)
+}
+
+func formatBytes(b float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+
+	i := 0
+	for b >= 1024 && i < len(units)-1 {
+		b /= 1024
+		i++
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", b, units[i])
+	}
+
+	return fmt.Sprintf("%.1f %s", b, units[i])
+}
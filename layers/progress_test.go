@@ -0,0 +1,116 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	layersBp "github.com/buildpack/libbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/logger"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestProgress(t *testing.T) {
+	spec.Run(t, "Progress", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var (
+			dependency buildpack.Dependency
+			info       bytes.Buffer
+			ls         layers.Layers
+			server     *ghttp.Server
+		)
+
+		it.Before(func() {
+			root := test.ScratchDir(t, "progress")
+
+			server = ghttp.NewServer()
+
+			dependency = buildpack.Dependency{
+				ID:      "test-id",
+				Version: internal.NewTestVersion(t, "1.0"),
+				SHA256:  "6f06dd0e26608013eff30bb1e951cda7de3fdd9e78e907470e0dd5c0ed25e273",
+				URI:     fmt.Sprintf("%s/test-path", server.URL()),
+			}
+
+			info.Reset()
+			ls = layers.NewLayers(layersBp.Layers{Root: root}, layersBp.Layers{Root: filepath.Join(root, "buildpack")},
+				buildpack.Buildpack{}, logger.NewFromWriters(nil, &info))
+			ls.DownloadProgress = layers.Progress{Interval: time.Nanosecond}
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("reports progress with a known size", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+
+			_, err := ls.DownloadLayer(dependency).Artifact()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(info.String()).To(MatchRegexp(`Downloaded 12 B of 12 B \(100%\) at .+/s, ETA 0s`))
+		})
+
+		it("reports progress with an unknown size", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+				w.(http.Flusher).Flush()
+				_, _ = w.Write([]byte("test-payload"))
+			})
+
+			_, err := ls.DownloadLayer(dependency).Artifact()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(info.String()).To(MatchRegexp(`Downloaded 12 B at .+/s`))
+		})
+
+		it("reports final progress once the download finishes", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+			ls.DownloadProgress = layers.Progress{Interval: time.Hour}
+
+			_, err := ls.DownloadLayer(dependency).Artifact()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(strings.Count(info.String(), "Downloaded")).To(Equal(1))
+			g.Expect(info.String()).To(MatchRegexp(`Downloaded 12 B of 12 B \(100%\) at .+/s, ETA 0s`))
+		})
+
+		it("does not report progress when disabled", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-payload"))
+			ls.DownloadProgress = layers.Progress{}
+
+			_, err := ls.DownloadLayer(dependency).Artifact()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(strings.Contains(info.String(), "Downloaded")).To(BeFalse())
+		})
+	}, spec.Report(report.Terminal{}))
+}
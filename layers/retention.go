@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/heroku/libhkbuildpack/helper"
+)
+
+const (
+	// RetentionBuildsEnv is the environment variable containing the number of builds an untouched cache-only layer is
+	// retained for.
+	RetentionBuildsEnv = "BP_LAYER_RETENTION_BUILDS"
+
+	// RetentionKeepEnv is the environment variable containing a comma separated list of layer name patterns that are
+	// never removed.
+	RetentionKeepEnv = "BP_LAYER_RETENTION_KEEP"
+
+	// RetentionDryRunEnv is the environment variable used to report untouched layers without removing them.
+	RetentionDryRunEnv = "BP_LAYER_RETENTION_DRY_RUN"
+
+	// LedgerLayer is the name of the cache-only layer whose metadata records the build in which each layer was last
+	// touched.  The lifecycle restores it from the cache along with the layers it records.
+	LedgerLayer = "layers-ledger"
+)
+
+// RetentionPolicy determines which untouched layers are removed by TouchedLayers.Cleanup.
+type RetentionPolicy struct {
+	// CacheBuilds is the number of builds an untouched cache-only layer is retained for.  Zero removes untouched
+	// layers immediately.
+	CacheBuilds int
+
+	// Keep are glob patterns (e.g. jdk-*) of layer names that are never removed.
+	Keep []string
+
+	// DryRun reports the layers that would be removed without removing them.
+	DryRun bool
+}
+
+// DefaultRetentionPolicy creates a new instance of RetentionPolicy, extracting values from the BP_LAYER_RETENTION_BUILDS,
+// BP_LAYER_RETENTION_KEEP, and BP_LAYER_RETENTION_DRY_RUN environment variables.
+func DefaultRetentionPolicy() (RetentionPolicy, error) {
+	var r RetentionPolicy
+
+	if s, ok := os.LookupEnv(RetentionBuildsEnv); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid %s %q: must be a non-negative integer", RetentionBuildsEnv, s)
+		}
+		r.CacheBuilds = n
+	}
+
+	for _, k := range strings.Split(os.Getenv(RetentionKeepEnv), ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+
+		if _, err := filepath.Match(k, ""); err != nil {
+			return RetentionPolicy{}, fmt.Errorf("invalid %s pattern %q", RetentionKeepEnv, k)
+		}
+
+		r.Keep = append(r.Keep, k)
+	}
+
+	dryRun, err := strconv.ParseBool(os.Getenv(RetentionDryRunEnv))
+	r.DryRun = err == nil && dryRun
+
+	return r, nil
+}
+
+// keeps returns whether a layer is never removed.
+func (r RetentionPolicy) keeps(name string) bool {
+	for _, k := range r.Keep {
+		if ok, _ := filepath.Match(k, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retains returns whether an untouched cache-only layer last touched in a build is retained in the current build.
+func (r RetentionPolicy) retains(lastTouched int, current int) bool {
+	return current-lastTouched <= r.CacheBuilds
+}
+
+// ledger records the build in which each layer was last touched.
+type ledger struct {
+	// Build is the number of the latest build.
+	Build int `toml:"build"`
+
+	// Layers maps layer names to the build in which they were last touched.
+	Layers map[string]int `toml:"layers"`
+}
+
+// ledgerLayer is the layer metadata the ledger is persisted in.
+type ledgerLayer struct {
+	Cache    bool   `toml:"cache"`
+	Metadata ledger `toml:"metadata"`
+}
+
+func readLedger(root string) (ledger, error) {
+	file := filepath.Join(root, LedgerLayer+".toml")
+	l := ledgerLayer{Metadata: ledger{Layers: make(map[string]int)}}
+
+	if exists, err := helper.FileExists(file); err != nil {
+		return ledger{}, err
+	} else if !exists {
+		return l.Metadata, nil
+	}
+
+	if _, err := toml.DecodeFile(file, &l); err != nil {
+		return ledger{}, fmt.Errorf("unable to read layers ledger %s: %s", file, err.Error())
+	}
+
+	if l.Metadata.Layers == nil {
+		l.Metadata.Layers = make(map[string]int)
+	}
+
+	return l.Metadata, nil
+}
+
+func (l ledger) write(root string) error {
+	if err := os.MkdirAll(filepath.Join(root, LedgerLayer), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(root, LedgerLayer+".toml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(ledgerLayer{Cache: true, Metadata: l})
+}
+
+// cacheOnly returns whether a layer's metadata marks it as only cached, not used for build or launch.
+func cacheOnly(metadata string) bool {
+	var flags struct {
+		Build  bool `toml:"build"`
+		Cache  bool `toml:"cache"`
+		Launch bool `toml:"launch"`
+	}
+
+	if _, err := toml.DecodeFile(metadata, &flags); err != nil {
+		return false
+	}
+
+	return flags.Cache && !flags.Build && !flags.Launch
+}
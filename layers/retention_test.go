@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers_test
+
+import (
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/layers"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	spec.Run(t, "RetentionPolicy", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		it("extracts policy from environment", func() {
+			defer test.ReplaceEnv(t, "BP_LAYER_RETENTION_BUILDS", "3")()
+			defer test.ReplaceEnv(t, "BP_LAYER_RETENTION_KEEP", "jdk-*, node_modules")()
+			defer test.ReplaceEnv(t, "BP_LAYER_RETENTION_DRY_RUN", "true")()
+
+			g.Expect(layers.DefaultRetentionPolicy()).To(Equal(layers.RetentionPolicy{
+				CacheBuilds: 3,
+				Keep:        []string{"jdk-*", "node_modules"},
+				DryRun:      true,
+			}))
+		})
+
+		it("returns error for invalid build count", func() {
+			defer test.ReplaceEnv(t, "BP_LAYER_RETENTION_BUILDS", "-1")()
+
+			_, err := layers.DefaultRetentionPolicy()
+			g.Expect(err).To(MatchError(`invalid BP_LAYER_RETENTION_BUILDS "-1": must be a non-negative integer`))
+		})
+
+		it("returns error for invalid pattern", func() {
+			defer test.ReplaceEnv(t, "BP_LAYER_RETENTION_BUILDS", "0")()
+			defer test.ReplaceEnv(t, "BP_LAYER_RETENTION_KEEP", "[")()
+
+			_, err := layers.DefaultRetentionPolicy()
+			g.Expect(err).To(MatchError(`invalid BP_LAYER_RETENTION_KEEP pattern "["`))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"time"
+)
+
+// DefaultRetry is the Retry used by Layers unless otherwise configured.
+var DefaultRetry = Retry{Attempts: 5, Backoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// Retry describes how a failed download is retried.
+type Retry struct {
+	// Attempts is the maximum number of times a download is attempted.  Values less than one are treated as one.
+	Attempts int
+
+	// Backoff is the delay before the first retry.  The delay doubles after each subsequent failure.
+	Backoff time.Duration
+
+	// MaxBackoff is the upper bound of the delay between attempts.  A zero value indicates no upper bound.
+	MaxBackoff time.Duration
+}
+
+func (r Retry) delay(attempt int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < attempt && (r.MaxBackoff == 0 || d < r.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+
+	return d
+}
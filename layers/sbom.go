@@ -0,0 +1,77 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/heroku/libhkbuildpack/helper"
+	"github.com/heroku/libhkbuildpack/sbom"
+)
+
+// WriteSBOM writes CycloneDX and SPDX bills-of-materials describing the components of the layer alongside the layer's
+// metadata (e.g. <layer>.sbom.cdx.json and <layer>.sbom.spdx.json).
+func (l Layer) WriteSBOM(components ...sbom.Component) error {
+	if l.planned(PlanMetadata, "bill-of-materials") {
+		return nil
+	}
+
+	l.Logger.Debug("Writing bill-of-materials for %s", filepath.Base(l.Root))
+
+	document := sbom.NewDocument(filepath.Base(l.Root), components...)
+
+	cdx, err := document.CycloneDX()
+	if err != nil {
+		return err
+	}
+
+	spdx, err := document.SPDX()
+	if err != nil {
+		return err
+	}
+
+	files := sbomFiles(l.Metadata)
+
+	if err := helper.WriteFile(files[0], 0644, "%s\n", cdx); err != nil {
+		return err
+	}
+
+	return helper.WriteFile(files[1], 0644, "%s\n", spdx)
+}
+
+// removeSBOM removes the bills-of-materials written alongside layer metadata.
+func removeSBOM(metadata string) error {
+	for _, f := range sbomFiles(metadata) {
+		if err := os.RemoveAll(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sbomFiles(metadata string) []string {
+	base := strings.TrimSuffix(metadata, ".toml")
+
+	return []string{
+		fmt.Sprintf("%s.sbom.%s", base, sbom.CycloneDXExtension),
+		fmt.Sprintf("%s.sbom.%s", base, sbom.SPDXExtension),
+	}
+}
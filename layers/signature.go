@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package layers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// verifySignature verifies a detached signature of file with a PEM encoded PKIX public key.  ECDSA and RSA (PKCS #1
+// v1.5) signatures are made over the SHA-256 digest of the file and Ed25519 signatures over its content.  The signature
+// may be either raw or base64 encoded.
+func verifySignature(file string, signature []byte, key []byte) error {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return fmt.Errorf("public key is not PEM encoded")
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		signature = decoded
+	}
+
+	switch k := public.(type) {
+	case *ecdsa.PublicKey:
+		digest, err := sha256Digest(file)
+		if err != nil {
+			return err
+		}
+
+		if !ecdsa.VerifyASN1(k, digest, signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		digest, err := sha256Digest(file)
+		if err != nil {
+			return err
+		}
+
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	case ed25519.PublicKey:
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if !ed25519.Verify(k, content, signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", public)
+	}
+
+	return nil
+}
+
+func sha256Digest(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
@@ -19,6 +19,7 @@ package layers
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/heroku/libhkbuildpack/internal"
@@ -30,17 +31,25 @@ type TouchedLayers struct {
 	// Root is the root location of all layers to inspect for unused layers.
 	Root string
 
+	// Plan records the layers that would be removed instead of removing them.  Plan mode is enabled when Plan is not
+	// nil.
+	Plan *Plan
+
+	// Retention is the policy determining which untouched layers are removed.
+	Retention RetentionPolicy
+
 	logger  *logger.Log
 	touched internal.Set
 }
 
-// Add registers that a given layer has been touched
+// Add registers that a given layer has been touched.  It is safe to call concurrently.
 func (t TouchedLayers) Add(metadata string) {
 	t.logger.Debug("Layer %s touched", metadata)
 	t.touched.Add(metadata)
 }
 
-// Cleanup removes all layers that have not been touched as part of this execution.
+// Cleanup removes all layers that have not been touched as part of this execution, subject to the retention policy.
+// The build in which each layer was last touched is recorded in a ledger persisted as a cache-only layer.
 func (t TouchedLayers) Cleanup() error {
 	candidates, err := t.candidates()
 	if err != nil {
@@ -52,22 +61,84 @@ func (t TouchedLayers) Cleanup() error {
 		t.logger.Debug("Touched Layers: %s", t.touched)
 	}
 
-	remove := candidates.Difference(t.touched)
-	if remove.Size() == 0 {
-		return nil
+	ledger, err := readLedger(t.Root)
+	if err != nil {
+		return err
+	}
+	ledger.Build++
+
+	current := make(map[string]int, len(ledger.Layers))
+	for c := range candidates.Iterator() {
+		name := layerName(c.(string))
+		if b, ok := ledger.Layers[name]; ok {
+			current[name] = b
+		} else {
+			current[name] = ledger.Build - 1
+		}
+	}
+	ledger.Layers = current
+
+	for f := range t.touched.Iterator() {
+		ledger.Layers[layerName(f.(string))] = ledger.Build
 	}
 
-	t.logger.FirstLine("%s unused layers", "Removing")
-	for r := range remove.Iterator() {
+	var remove []string
+	for r := range candidates.Difference(t.touched).Iterator() {
 		f := r.(string)
-		t.logger.SubsequentLine(strings.TrimSuffix(filepath.Base(f), ".toml"))
+		name := layerName(f)
+
+		if t.Retention.keeps(name) {
+			t.logger.Debug("Keeping layer %s matching retention pattern", name)
+			continue
+		}
+
+		if cacheOnly(f) && t.Retention.retains(ledger.Layers[name], ledger.Build) {
+			t.logger.Debug("Keeping cache layer %s last touched in build %d", name, ledger.Layers[name])
+			continue
+		}
+
+		remove = append(remove, f)
+	}
+	sort.Strings(remove)
+
+	if t.Plan != nil {
+		for _, f := range remove {
+			t.Plan.Record(layerName(f), PlanRemove, "unused layer")
+		}
+
+		return nil
+	}
+
+	if t.Retention.DryRun {
+		if len(remove) > 0 {
+			t.logger.FirstLine("%s unused layers (dry run)", "Would remove")
+			for _, f := range remove {
+				t.logger.SubsequentLine("%s", layerName(f))
+			}
+		}
+
+		return ledger.write(t.Root)
+	}
+
+	if len(remove) > 0 {
+		t.logger.FirstLine("%s unused layers", "Removing")
+	}
+
+	for _, f := range remove {
+		t.logger.SubsequentLine("%s", layerName(f))
 
 		if err := os.RemoveAll(f); err != nil {
 			return err
 		}
+
+		if err := removeSBOM(f); err != nil {
+			return err
+		}
+
+		delete(ledger.Layers, layerName(f))
 	}
 
-	return nil
+	return ledger.write(t.Root)
 }
 
 func (t TouchedLayers) candidates() (internal.Set, error) {
@@ -81,8 +152,9 @@ func (t TouchedLayers) candidates() (internal.Set, error) {
 	app := filepath.Join(t.Root, "app.toml")
 	launch := filepath.Join(t.Root, "launch.toml") // TODO: Remove once launch.toml removed from lifecycle
 	store := filepath.Join(t.Root, "store.toml")
+	ledger := filepath.Join(t.Root, LedgerLayer+".toml")
 	for _, f := range files {
-		if f != app && f != launch && f != store {
+		if f != app && f != launch && f != store && f != ledger {
 			candidates.Add(f)
 		}
 	}
@@ -92,5 +164,9 @@ func (t TouchedLayers) candidates() (internal.Set, error) {
 
 // NewTouchedLayers creates a new instance that monitors a given root.
 func NewTouchedLayers(root string, logger *logger.Log) TouchedLayers {
-	return TouchedLayers{root, logger, internal.NewSet()}
+	return TouchedLayers{root, nil, RetentionPolicy{}, logger, internal.NewSet()}
+}
+
+func layerName(metadata string) string {
+	return strings.TrimSuffix(filepath.Base(metadata), ".toml")
 }
@@ -17,9 +17,13 @@
 package layers_test
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/BurntSushi/toml"
 	"github.com/heroku/libhkbuildpack/layers"
 	"github.com/heroku/libhkbuildpack/logger"
 	"github.com/heroku/libhkbuildpack/test"
@@ -60,6 +64,17 @@ func TestTouchedLayers(t *testing.T) {
 			g.Expect(filepath.Join(root, "test-layer.toml")).NotTo(BeARegularFile())
 		})
 
+		it("removes bills-of-materials of untouched layers", func() {
+			test.TouchFile(t, root, "test-layer.toml")
+			test.TouchFile(t, root, "test-layer.sbom.cdx.json")
+			test.TouchFile(t, root, "test-layer.sbom.spdx.json")
+
+			g.Expect(touched.Cleanup()).To(Succeed())
+
+			g.Expect(filepath.Join(root, "test-layer.sbom.cdx.json")).NotTo(BeAnExistingFile())
+			g.Expect(filepath.Join(root, "test-layer.sbom.spdx.json")).NotTo(BeAnExistingFile())
+		})
+
 		it("does not remove app.toml", func() {
 			test.TouchFile(t, root, "app.toml")
 
@@ -83,5 +98,120 @@ func TestTouchedLayers(t *testing.T) {
 
 			g.Expect(filepath.Join(root, "store.toml")).To(BeARegularFile())
 		})
+
+		it("records touched layers in ledger", func() {
+			test.TouchFile(t, root, "test-layer.toml")
+			touched.Add(filepath.Join(root, "test-layer.toml"))
+
+			g.Expect(touched.Cleanup()).To(Succeed())
+
+			g.Expect(filepath.Join(root, "layers-ledger.toml")).To(test.HaveContent(`cache = true
+
+[metadata]
+  build = 1
+  [metadata.layers]
+    test-layer = 1
+`))
+		})
+
+		it("does not remove layers matching keep patterns", func() {
+			test.TouchFile(t, root, "jdk-11.toml")
+			test.TouchFile(t, root, "test-layer.toml")
+			touched.Retention = layers.RetentionPolicy{Keep: []string{"jdk-*"}}
+
+			g.Expect(touched.Cleanup()).To(Succeed())
+
+			g.Expect(filepath.Join(root, "jdk-11.toml")).To(BeARegularFile())
+			g.Expect(filepath.Join(root, "test-layer.toml")).NotTo(BeARegularFile())
+		})
+
+		it("retains untouched cache layers for a number of builds", func() {
+			test.WriteFile(t, filepath.Join(root, "cache-layer.toml"), "cache = true")
+			test.WriteFile(t, filepath.Join(root, "launch-layer.toml"), "cache = true\nlaunch = true")
+
+			cleanup := func() {
+				t.Helper()
+
+				touched := layers.NewTouchedLayers(root, logger.New(nil))
+				touched.Retention = layers.RetentionPolicy{CacheBuilds: 2}
+				g.Expect(touched.Cleanup()).To(Succeed())
+			}
+
+			cleanup()
+			g.Expect(filepath.Join(root, "launch-layer.toml")).NotTo(BeARegularFile())
+			g.Expect(filepath.Join(root, "cache-layer.toml")).To(BeARegularFile())
+
+			cleanup()
+			g.Expect(filepath.Join(root, "cache-layer.toml")).To(BeARegularFile())
+
+			cleanup()
+			g.Expect(filepath.Join(root, "cache-layer.toml")).NotTo(BeARegularFile())
+		})
+
+		it("retains the ledger when the lifecycle only restores cache layers", func() {
+			test.WriteFile(t, filepath.Join(root, "cache-layer.toml"), "cache = true")
+
+			cleanup := func() {
+				t.Helper()
+
+				touched := layers.NewTouchedLayers(root, logger.New(nil))
+				touched.Retention = layers.RetentionPolicy{CacheBuilds: 1}
+				g.Expect(touched.Cleanup()).To(Succeed())
+			}
+
+			restore := func() {
+				t.Helper()
+
+				files, err := ioutil.ReadDir(root)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				for _, f := range files {
+					name := strings.TrimSuffix(f.Name(), ".toml")
+					var flags struct {
+						Cache bool `toml:"cache"`
+					}
+					if _, err := toml.DecodeFile(filepath.Join(root, name+".toml"), &flags); err == nil && flags.Cache {
+						continue
+					}
+
+					g.Expect(os.RemoveAll(filepath.Join(root, f.Name()))).To(Succeed())
+				}
+			}
+
+			cleanup()
+			g.Expect(filepath.Join(root, "cache-layer.toml")).To(BeARegularFile())
+
+			restore()
+			cleanup()
+			g.Expect(filepath.Join(root, "cache-layer.toml")).NotTo(BeARegularFile())
+		})
+
+		it("only reports layers in dry run", func() {
+			test.TouchFile(t, root, "test-layer.toml")
+			touched.Retention = layers.RetentionPolicy{DryRun: true}
+
+			g.Expect(touched.Cleanup()).To(Succeed())
+
+			g.Expect(filepath.Join(root, "test-layer.toml")).To(BeARegularFile())
+		})
+
+		it("advances the ledger in dry run", func() {
+			test.WriteFile(t, filepath.Join(root, "cache-layer.toml"), "cache = true")
+
+			cleanup := func(dryRun bool) {
+				t.Helper()
+
+				touched := layers.NewTouchedLayers(root, logger.New(nil))
+				touched.Retention = layers.RetentionPolicy{CacheBuilds: 1, DryRun: dryRun}
+				g.Expect(touched.Cleanup()).To(Succeed())
+			}
+
+			cleanup(true)
+			cleanup(true)
+			g.Expect(filepath.Join(root, "cache-layer.toml")).To(BeARegularFile())
+
+			cleanup(false)
+			g.Expect(filepath.Join(root, "cache-layer.toml")).NotTo(BeARegularFile())
+		})
 	}, spec.Report(report.Terminal{}))
 }
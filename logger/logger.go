@@ -57,7 +57,7 @@ func NewFromWriters(debug, info io.Writer) *Log {
 }
 
 // Error prints an error message to the console if an info logger is provided.
-func (l Log) Error(format string, args ...interface{}) {
+func (l *Log) Error(format string, args ...interface{}) {
 	if format == "" {
 		l.printInfo("")
 		return
@@ -67,19 +67,19 @@ func (l Log) Error(format string, args ...interface{}) {
 }
 
 // FirstLine prints a line with a leading arrow.
-func (l Log) FirstLine(format string, args ...interface{}) {
+func (l *Log) FirstLine(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	l.printInfo("%s %s", prefix, msg)
 }
 
 // SubsequentLine prints indented output without the leading arrow.
-func (l Log) SubsequentLine(format string, args ...interface{}) {
+func (l *Log) SubsequentLine(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	l.printInfo("%s%s", indent, msg)
 }
 
 // Warning prints a warning message
-func (l Log) Warning(format string, args ...interface{}) {
+func (l *Log) Warning(format string, args ...interface{}) {
 	if format == "" {
 		l.printInfo("")
 		return
@@ -88,7 +88,7 @@ func (l Log) Warning(format string, args ...interface{}) {
 	l.printInfo("%s %s %s", prefix, warning, msg)
 }
 
-func (l Log) Debug(format string, args ...interface{}) {
+func (l *Log) Debug(format string, args ...interface{}) {
 	if format == "" {
 		l.printDebug("")
 		return
@@ -97,7 +97,7 @@ func (l Log) Debug(format string, args ...interface{}) {
 	l.printDebug("%s %s %s", prefix, debug, msg)
 }
 
-func (l Log) Info(format string, args ...interface{}) {
+func (l *Log) Info(format string, args ...interface{}) {
 	if format == "" {
 		l.printInfo("")
 		return
@@ -106,7 +106,7 @@ func (l Log) Info(format string, args ...interface{}) {
 	l.printInfo("%s %s %s", prefix, info, msg)
 }
 
-func (l Log) PrettyIdentity(v Identifiable) string {
+func (l *Log) PrettyIdentity(v Identifiable) string {
 	if v == nil {
 		return ""
 	}
@@ -122,19 +122,19 @@ func (l Log) PrettyIdentity(v Identifiable) string {
 	return sb.String()
 }
 
-func (l Log) IsDebugEnabled() bool {
+func (l *Log) IsDebugEnabled() bool {
 	l.Lock()
 	defer l.Unlock()
 	return l.debug != nil
 }
 
-func (l Log) printDebug(format string, args ...interface{}) {
+func (l *Log) printDebug(format string, args ...interface{}) {
 	l.Lock()
 	defer l.Unlock()
 	print(l.debug, format, args...)
 }
 
-func (l Log) printInfo(format string, args ...interface{}) {
+func (l *Log) printInfo(format string, args ...interface{}) {
 	l.Lock()
 	defer l.Unlock()
 	print(l.info, format, args...)
@@ -54,6 +54,12 @@ type Packager struct {
 
 
 func New(bpDir, outputDir, cacheDir string) (Packager, error) {
+	return NewWithDownloadCache(bpDir, outputDir, cacheDir, layers.DefaultDownloadCache())
+}
+
+// NewWithDownloadCache creates a Packager whose dependencies are stored in, and reused from, a content addressed
+// download cache shared with builds on the same host.
+func NewWithDownloadCache(bpDir, outputDir, cacheDir string, downloadCache layers.DownloadCache) (Packager, error) {
 	l, err := loggerBp.DefaultLogger("")
 	if err != nil {
 		return Packager{}, err
@@ -71,9 +77,15 @@ func New(bpDir, outputDir, cacheDir string) (Packager, error) {
 		return Packager{}, err
 	}
 
+	ls := layers.NewLayers(layersBp.NewLayers(depCache, l), layersBp.NewLayers(depCache, l), b, log)
+	ls.DownloadCache = downloadCache
+	ls.DownloadStarted = func(dependency buildpack.Dependency) {
+		log.FirstLine("Caching %s", log.PrettyIdentity(dependency))
+	}
+
 	return Packager{
 		b,
-		layers.NewLayers(layersBp.NewLayers(depCache, l), layersBp.NewLayers(depCache, l), b, log),
+		ls,
 		log,
 		outputDir,
 	}, nil
@@ -84,9 +96,49 @@ type pkgFile struct {
 	packagePath string
 }
 
+// Check ensures that the license types of all dependencies are SPDX license expressions of identifiers on the SPDX
+// license list.  Licenses that are not on the list must be declared with a LicenseRef-<id> identifier.
+func (p Packager) Check() error {
+	deps, err := p.buildpack.Dependencies()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, d := range deps {
+		for _, l := range d.Licenses {
+			if l.Type == "" {
+				continue
+			}
+
+			e, err := l.Expression()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s: %s", d.ID, d.Version.Original(), err))
+				continue
+			}
+
+			for _, u := range e.Unknown {
+				problems = append(problems, fmt.Sprintf("%s %s: unknown SPDX license identifier %q", d.ID, d.Version.Original(), u))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid dependency licenses (use LicenseRef-<id> for licenses not on SPDX license list %s):\n  %s",
+			buildpack.SPDXLicenseListVersion, strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+// Create packages the buildpack into the output directory, checking the licenses of its dependencies first.
 func (p Packager) Create(cache bool) error {
 	p.logger.FirstLine("Packaging %s", p.logger.PrettyIdentity(p.buildpack))
 
+	if err := p.Check(); err != nil {
+		return err
+	}
+
 	if err := p.prePackage(); err != nil {
 		return err
 	}
@@ -128,24 +180,27 @@ func (p Packager) cacheDependencies() ([]pkgFile, error) {
 		return nil, err
 	}
 
+	var downloadLayers []layers.DownloadLayer
 	for _, dep := range deps {
-		p.logger.FirstLine("Caching %s", p.logger.PrettyIdentity(dep))
+		downloadLayers = append(downloadLayers, p.layers.DownloadLayer(dep))
+	}
 
-		layer := p.layers.DownloadLayer(dep)
+	artifacts, err := layers.DownloadAll(downloadLayers, p.layers.DownloadConcurrency)
+	if err != nil {
+		return nil, err
+	}
 
-		a, err := layer.Artifact()
-		if err != nil {
-			return nil, err
-		}
+	for i, dep := range deps {
+		a := artifacts[i]
 
 		f := pkgFile{
 			path:        a,
-			packagePath: filepath.Join(buildpack.CacheRoot, dep.SHA256, filepath.Base(a)),
+			packagePath: filepath.Join(buildpack.CacheRoot, dep.CacheKey(), filepath.Base(a)),
 		}
 
 		metaF := pkgFile{
-			path:        layer.Metadata,
-			packagePath: filepath.Join(buildpack.CacheRoot, dep.SHA256+".toml"),
+			path:        downloadLayers[i].Metadata,
+			packagePath: filepath.Join(buildpack.CacheRoot, dep.CacheKey()+".toml"),
 		}
 
 		files = append(files, f, metaF)
@@ -266,16 +321,20 @@ func (p Packager) depsSummary(out *string) error {
 		Version string
 	}
 
+	type depValue struct {
+		Stacks          buildpack.Stacks
+		DeprecationDate string
+		EOLDate         string
+	}
+
 	bpMetadata := p.buildpack.Metadata
 	deps, ok := bpMetadata["dependencies"].([]map[string]interface{})
 	if !ok || len(deps) == 0 {
 		return nil
 	}
 
-	*out = "\nPackaged binaries:\n\n"
-	*out += "| name | version | stacks |\n|-|-|-|\n"
-
-	depMap := map[depKey]buildpack.Stacks{}
+	lifecycle := false
+	depMap := map[depKey]depValue{}
 	for _, d := range deps {
 		dep, err := buildpack.NewDependency(d)
 		if err != nil {
@@ -285,12 +344,25 @@ func (p Packager) depsSummary(out *string) error {
 			ID:      dep.ID,
 			Version: dep.Version.Version.String(),
 		}
-		if _, ok := depMap[depKey]; !ok {
-			depMap[depKey] = dep.Stacks
-		} else {
-			depMap[depKey] = append(depMap[depKey], dep.Stacks...)
+		value := depMap[depKey]
+		value.Stacks = append(value.Stacks, dep.Stacks...)
+		if dep.DeprecationDate != "" {
+			value.DeprecationDate = dep.DeprecationDate
+		}
+		if dep.EOLDate != "" {
+			value.EOLDate = dep.EOLDate
 		}
+		depMap[depKey] = value
+		lifecycle = lifecycle || dep.DeprecationDate != "" || dep.EOLDate != ""
 	}
+
+	*out = "\nPackaged binaries:\n\n"
+	if lifecycle {
+		*out += "| name | version | stacks | deprecation date | eol date |\n|-|-|-|-|-|\n"
+	} else {
+		*out += "| name | version | stacks |\n|-|-|-|\n"
+	}
+
 	depKeyArray := make([]depKey, 0)
 	for key, _ := range depMap {
 		depKeyArray = append(depKeyArray, key)
@@ -315,12 +387,16 @@ func (p Packager) depsSummary(out *string) error {
 	})
 
 	for _, dKey := range depKeyArray {
-		stacks := depMap[dKey]
+		value := depMap[dKey]
 		stackStringArray := []string{}
-		for _, stack := range stacks {
+		for _, stack := range value.Stacks {
 			stackStringArray = append(stackStringArray, string(stack))
 		}
-		*out += fmt.Sprintf("| %s | %s | %s |\n", dKey.ID, dKey.Version, strings.Join(stackStringArray, ", "))
+		*out += fmt.Sprintf("| %s | %s | %s |", dKey.ID, dKey.Version, strings.Join(stackStringArray, ", "))
+		if lifecycle {
+			*out += fmt.Sprintf(" %s | %s |", value.DeprecationDate, value.EOLDate)
+		}
+		*out += "\n"
 	}
 
 	return nil
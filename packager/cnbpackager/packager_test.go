@@ -136,6 +136,48 @@ id = 'stack-id'
 		})
 	})
 
+	when("check", func() {
+		writeLicense := func(license string) {
+			b, err := ioutil.ReadFile(filepath.Join(cnbDir, "buildpack.toml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			b = append(b, []byte(fmt.Sprintf(`
+[[metadata.dependencies.licenses]]
+type = "%s"
+`, license))...)
+			Expect(ioutil.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), b, 0666)).To(Succeed())
+		}
+
+		it("succeeds with known licenses", func() {
+			writeLicense("apache-2.0 OR MIT")
+
+			pkgr, err = cnbpackager.New(cnbDir, outputDir, cacheDir)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pkgr.Check()).To(Succeed())
+		})
+
+		it("fails with unknown licenses", func() {
+			writeLicense("Apache2")
+
+			pkgr, err = cnbpackager.New(cnbDir, outputDir, cacheDir)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pkgr.Check()).To(MatchError(ContainSubstring(`dependency-id 1.0.0: unknown SPDX license identifier "Apache2"`)))
+			Expect(pkgr.Create(false)).NotTo(Succeed())
+			Expect(filepath.Join(outputDir, "buildpack.toml")).NotTo(BeAnExistingFile())
+		})
+
+		it("fails with invalid license expressions", func() {
+			writeLicense("Apache License")
+
+			pkgr, err = cnbpackager.New(cnbDir, outputDir, cacheDir)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pkgr.Check()).To(MatchError(ContainSubstring(`invalid license expression "Apache License"`)))
+		})
+	})
+
 	when("summary", func() {
 		it("Returns a package Summary of the CNB directory", func() {
 			fakeCnbDir := filepath.Join("testdata", "summary-testdata", "fake-cnb")
@@ -183,6 +225,31 @@ Packaged binaries:
 
 Supported stacks:
 
+| name |
+|-|
+| stack1 |
+| stack2 |
+`
+
+			summary, err := pkgr.Summary()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(summary).To(Equal(solution))
+		})
+
+		it("includes dependency lifecycle dates", func() {
+			fakeCnbDir := filepath.Join("testdata", "summary-testdata", "fake-cnb-with-lifecycle")
+			pkgr, err = cnbpackager.New(fakeCnbDir, "", "")
+			Expect(err).ToNot(HaveOccurred())
+			solution := `
+Packaged binaries:
+
+| name | version | stacks | deprecation date | eol date |
+|-|-|-|-|-|
+| dep1 | 4.5.6 | stack1 | 2020-01-01 | 2020-06-01 |
+| dep2 | 7.8.9 | stack2 |  |  |
+
+Supported stacks:
+
 | name |
 |-|
 | stack1 |
@@ -25,6 +25,7 @@ import (
 	"os/user"
 	"path/filepath"
 
+	"github.com/heroku/libhkbuildpack/layers"
 	"github.com/heroku/libhkbuildpack/packager/cnbpackager"
 )
 
@@ -41,6 +42,7 @@ func main() {
 	uncached := pflags.Bool("uncached", false, "cache dependencies")
 	archive := pflags.Bool("archive", false, "tar resulting buildpack")
 	summary := pflags.Bool("summary", false, "print buildpack.toml summary to stdout")
+	check := pflags.Bool("check", false, "check dependency licenses against the SPDX license list")
 	globalCache := pflags.Bool("global_cache", false, fmt.Sprintf("use global cache dir at %s", globalCacheDir))
 
 	if err := pflags.Parse(os.Args[1:]); err != nil {
@@ -65,7 +67,7 @@ func main() {
 
 	var pkgr cnbpackager.Packager
 	if *globalCache {
-		pkgr, err = cnbpackager.New(".", destination, globalCacheDir) // Default bpDir is "."
+		pkgr, err = cnbpackager.NewWithDownloadCache(".", destination, globalCacheDir, layers.DownloadCache{Root: filepath.Join(globalCacheDir, "downloads")}) // Default bpDir is "."
 	} else {
 		pkgr, err = cnbpackager.New(".", destination, localCacheDir) // Default bpDir is "."
 	}
@@ -85,6 +87,14 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *check {
+		if err := pkgr.Check(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed check: %s\n", err)
+			os.Exit(104)
+		}
+		os.Exit(0)
+	}
+
 	if err := pkgr.Create(!*uncached); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Failed to create: %s\n", err)
 		os.Exit(102)
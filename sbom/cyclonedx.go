@@ -0,0 +1,165 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+)
+
+// CycloneDXExtension is the file extension of CycloneDX JSON documents.
+const CycloneDXExtension = "cdx.json"
+
+type cdxDocument struct {
+	BOMFormat   string         `json:"bomFormat"`
+	SpecVersion string         `json:"specVersion"`
+	Version     int            `json:"version"`
+	Metadata    cdxMetadata    `json:"metadata"`
+	Components  []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     []cdxTool    `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Name string `json:"name"`
+}
+
+type cdxComponent struct {
+	BOMRef             string                 `json:"bom-ref,omitempty"`
+	Type               string                 `json:"type"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	PURL               string                 `json:"purl,omitempty"`
+	CPE                string                 `json:"cpe,omitempty"`
+	Hashes             []cdxHash              `json:"hashes,omitempty"`
+	Licenses           []cdxLicenseChoice     `json:"licenses,omitempty"`
+	ExternalReferences []cdxExternalReference `json:"externalReferences,omitempty"`
+}
+
+type cdxHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cdxLicenseChoice struct {
+	License cdxLicense `json:"license"`
+}
+
+type cdxLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cdxExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// CycloneDX returns the document as CycloneDX 1.4 JSON.
+func (d Document) CycloneDX() ([]byte, error) {
+	doc := cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: created().Format("2006-01-02T15:04:05Z"),
+			Tools:     []cdxTool{{Name: "libhkbuildpack"}},
+			Component: cdxComponent{Type: "application", Name: d.Name},
+		},
+		Components: []cdxComponent{},
+	}
+
+	for _, c := range d.Components {
+		component := cdxComponent{
+			BOMRef:  bomRef(c),
+			Type:    "library",
+			Name:    name(c),
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+
+		if len(c.CPEs) > 0 {
+			component.CPE = c.CPEs[0]
+		}
+
+		for _, s := range c.Checksums {
+			component.Hashes = append(component.Hashes, cdxHash{cdxAlgorithm(s.Algorithm()), s.Hex()})
+		}
+
+		for _, l := range c.Licenses {
+			component.Licenses = append(component.Licenses, cdxLicenseChoice{newCDXLicense(l)})
+		}
+
+		if c.URI != "" {
+			component.ExternalReferences = append(component.ExternalReferences, cdxExternalReference{"distribution", c.URI})
+		}
+
+		doc.Components = append(doc.Components, component)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// newCDXLicense identifies a license by its SPDX identifier when the type is a single known identifier, and by name
+// otherwise.
+func newCDXLicense(license buildpack.License) cdxLicense {
+	if e, err := license.Expression(); err == nil && len(e.Unknown) == 0 && !strings.ContainsAny(e.Normalized, " ()") {
+		return cdxLicense{ID: e.Normalized, URL: license.URI}
+	}
+
+	n := license.Type
+	if n == "" {
+		n = license.URI
+	}
+
+	return cdxLicense{Name: n, URL: license.URI}
+}
+
+func bomRef(component Component) string {
+	if component.PURL != "" {
+		return component.PURL
+	}
+
+	return fmt.Sprintf("%s@%s", component.ID, component.Version)
+}
+
+func cdxAlgorithm(algorithm string) string {
+	switch algorithm {
+	case "md5":
+		return "MD5"
+	case "sha1":
+		return "SHA-1"
+	default:
+		return fmt.Sprintf("SHA-%s", strings.TrimPrefix(algorithm, "sha"))
+	}
+}
+
+func name(component Component) string {
+	if component.Name != "" {
+		return component.Name
+	}
+
+	return component.ID
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sbom converts contributed dependencies into software bills-of-materials in the CycloneDX and SPDX JSON
+// formats.  Documents are deterministic: components are sorted and no random identifiers or wall-clock timestamps are
+// included, so the same dependencies always produce the same bytes.
+package sbom
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+)
+
+// SourceDateEpochEnv is the environment variable used to set the creation time of documents, as seconds since the
+// Unix epoch.
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// DefaultCreated is the creation time of documents when SOURCE_DATE_EPOCH is not set.
+var DefaultCreated = time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
+
+// Component is a software component described by a bill-of-materials.
+type Component struct {
+	// ID is the id of the component.
+	ID string
+
+	// Name is the name of the component.
+	Name string
+
+	// Version is the version of the component.
+	Version string
+
+	// URI is the location the component was downloaded from.
+	URI string
+
+	// PURL is the package URL of the component.
+	PURL string
+
+	// CPEs are the Common Platform Enumeration names of the component.
+	CPEs []string
+
+	// Checksums are the digests of the component's artifact.
+	Checksums []buildpack.Checksum
+
+	// Licenses are the licenses the component is distributed under.
+	Licenses buildpack.Licenses
+}
+
+// NewComponent creates a Component from a dependency.
+func NewComponent(dependency buildpack.Dependency) Component {
+	c := Component{
+		ID:        dependency.ID,
+		Name:      dependency.Name,
+		URI:       dependency.URI,
+		PURL:      dependency.PURL,
+		CPEs:      dependency.CPEs,
+		Checksums: dependency.Checksums(),
+		Licenses:  dependency.Licenses,
+	}
+
+	if dependency.Version.Version != nil {
+		c.Version = dependency.Version.Original()
+	}
+
+	return c
+}
+
+// Document is a software bill-of-materials.
+type Document struct {
+	// Name is the name of the document, typically the name of the layer it describes.
+	Name string
+
+	// Components are the components described by the document.
+	Components []Component
+}
+
+// NewDocument creates a Document with the components sorted by id and version.
+func NewDocument(name string, components ...Component) Document {
+	c := append([]Component(nil), components...)
+
+	sort.SliceStable(c, func(i, j int) bool {
+		if c[i].ID != c[j].ID {
+			return c[i].ID < c[j].ID
+		}
+
+		return c[i].Version < c[j].Version
+	})
+
+	return Document{name, c}
+}
+
+func created() time.Time {
+	if s, ok := os.LookupEnv(SourceDateEpochEnv); ok {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Unix(n, 0).UTC()
+		}
+	}
+
+	return DefaultCreated
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+	"github.com/heroku/libhkbuildpack/internal"
+	"github.com/heroku/libhkbuildpack/sbom"
+	"github.com/heroku/libhkbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestSBOM(t *testing.T) {
+	spec.Run(t, "SBOM", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := NewGomegaWithT(t)
+
+		var document sbom.Document
+
+		decode := func(b []byte, err error) map[string]interface{} {
+			g.Expect(err).NotTo(HaveOccurred())
+
+			var m map[string]interface{}
+			g.Expect(json.Unmarshal(b, &m)).To(Succeed())
+			return m
+		}
+
+		it.Before(func() {
+			document = sbom.NewDocument("test-layer",
+				sbom.NewComponent(buildpack.Dependency{
+					ID:       "test-id-2",
+					Name:     "test-name-2",
+					Version:  internal.NewTestVersion(t, "2.0"),
+					URI:      "https://test.com/test-path-2",
+					SHA256:   "test-sha256",
+					PURL:     "pkg:generic/test-name-2@2.0",
+					CPEs:     []string{"cpe:2.3:a:test-vendor:test-name-2:2.0:*:*:*:*:*:*:*"},
+					Licenses: buildpack.Licenses{{Type: "apache-2.0"}, {Type: "MIT OR test-license"}},
+				}),
+				sbom.Component{ID: "test-id-1", Name: "test-name-1", Version: "1.0"},
+			)
+		})
+
+		it("sorts components", func() {
+			g.Expect(document.Components[0].ID).To(Equal("test-id-1"))
+			g.Expect(document.Components[1].ID).To(Equal("test-id-2"))
+		})
+
+		when("CycloneDX", func() {
+
+			it("creates document", func() {
+				m := decode(document.CycloneDX())
+
+				g.Expect(m).To(HaveKeyWithValue("bomFormat", "CycloneDX"))
+				g.Expect(m).To(HaveKeyWithValue("specVersion", "1.4"))
+				g.Expect(m["components"]).To(HaveLen(2))
+
+				c := m["components"].([]interface{})[1].(map[string]interface{})
+				g.Expect(c).To(HaveKeyWithValue("bom-ref", "pkg:generic/test-name-2@2.0"))
+				g.Expect(c).To(HaveKeyWithValue("name", "test-name-2"))
+				g.Expect(c).To(HaveKeyWithValue("version", "2.0"))
+				g.Expect(c).To(HaveKeyWithValue("purl", "pkg:generic/test-name-2@2.0"))
+				g.Expect(c).To(HaveKeyWithValue("cpe", "cpe:2.3:a:test-vendor:test-name-2:2.0:*:*:*:*:*:*:*"))
+				g.Expect(c["hashes"]).To(ConsistOf(map[string]interface{}{"alg": "SHA-256", "content": "test-sha256"}))
+				g.Expect(c["licenses"]).To(ConsistOf(
+					map[string]interface{}{"license": map[string]interface{}{"id": "Apache-2.0"}},
+					map[string]interface{}{"license": map[string]interface{}{"name": "MIT OR test-license"}},
+				))
+			})
+
+			it("is deterministic", func() {
+				a, err := document.CycloneDX()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				b, err := sbom.NewDocument("test-layer", document.Components[1], document.Components[0]).CycloneDX()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(a).To(Equal(b))
+			})
+		})
+
+		when("SPDX", func() {
+
+			it("creates document", func() {
+				m := decode(document.SPDX())
+
+				g.Expect(m).To(HaveKeyWithValue("spdxVersion", "SPDX-2.2"))
+				g.Expect(m).To(HaveKeyWithValue("SPDXID", "SPDXRef-DOCUMENT"))
+				g.Expect(m["documentNamespace"]).To(HavePrefix("%s/test-layer-", sbom.SPDXNamespace))
+				g.Expect(m["creationInfo"]).To(HaveKeyWithValue("created", "1980-01-01T00:00:01Z"))
+				g.Expect(m["packages"]).To(HaveLen(2))
+				g.Expect(m["relationships"]).To(HaveLen(2))
+
+				p := m["packages"].([]interface{})[1].(map[string]interface{})
+				g.Expect(p).To(HaveKeyWithValue("SPDXID", "SPDXRef-Package-2-test-id-2"))
+				g.Expect(p).To(HaveKeyWithValue("versionInfo", "2.0"))
+				g.Expect(p).To(HaveKeyWithValue("downloadLocation", "https://test.com/test-path-2"))
+				g.Expect(p).To(HaveKeyWithValue("licenseDeclared", "Apache-2.0 AND LicenseRef-MIT-OR-test-license"))
+				g.Expect(p["checksums"]).To(ConsistOf(map[string]interface{}{"algorithm": "SHA256", "checksumValue": "test-sha256"}))
+				g.Expect(p["externalRefs"]).To(ConsistOf(
+					map[string]interface{}{"referenceCategory": "PACKAGE_MANAGER", "referenceType": "purl", "referenceLocator": "pkg:generic/test-name-2@2.0"},
+					map[string]interface{}{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:test-vendor:test-name-2:2.0:*:*:*:*:*:*:*"},
+				))
+
+				p = m["packages"].([]interface{})[0].(map[string]interface{})
+				g.Expect(p).To(HaveKeyWithValue("downloadLocation", "NOASSERTION"))
+				g.Expect(p).To(HaveKeyWithValue("licenseDeclared", "NOASSERTION"))
+			})
+
+			it("declares unknown licenses as license references", func() {
+				document = sbom.NewDocument("test-layer", sbom.Component{
+					ID: "test-id",
+					Licenses: buildpack.Licenses{
+						{Type: "Apache2"},
+						{Type: "Apache License", URI: "https://test.com/license"},
+						{Type: "MIT OR LicenseRef-test"},
+					},
+				})
+
+				m := decode(document.SPDX())
+
+				p := m["packages"].([]interface{})[0].(map[string]interface{})
+				g.Expect(p).To(HaveKeyWithValue("licenseDeclared", "LicenseRef-Apache2 AND LicenseRef-Apache-License AND (MIT OR LicenseRef-test)"))
+				g.Expect(m["hasExtractedLicensingInfos"]).To(Equal([]interface{}{
+					map[string]interface{}{"licenseId": "LicenseRef-Apache-License", "name": "Apache License", "extractedText": "Apache License: https://test.com/license"},
+					map[string]interface{}{"licenseId": "LicenseRef-Apache2", "name": "Apache2", "extractedText": "Apache2"},
+					map[string]interface{}{"licenseId": "LicenseRef-test", "extractedText": "NOASSERTION"},
+				}))
+			})
+
+			it("uses SOURCE_DATE_EPOCH", func() {
+				defer test.ReplaceEnv(t, sbom.SourceDateEpochEnv, "86400")()
+
+				m := decode(document.SPDX())
+				g.Expect(m["creationInfo"]).To(HaveKeyWithValue("created", "1970-01-02T00:00:00Z"))
+			})
+
+			it("is deterministic", func() {
+				a, err := document.SPDX()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				b, err := document.SPDX()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				g.Expect(a).To(Equal(b))
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
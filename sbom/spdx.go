@@ -0,0 +1,219 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/heroku/libhkbuildpack/buildpack"
+)
+
+// SPDXExtension is the file extension of SPDX JSON documents.
+const SPDXExtension = "spdx.json"
+
+// SPDXNamespace is the prefix of the namespace of SPDX documents.
+const SPDXNamespace = "https://github.com/heroku/libhkbuildpack/spdx"
+
+const noAssertion = "NOASSERTION"
+
+var spdxInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+	ExtractedLicenses []spdxLicense      `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxLicense struct {
+	LicenseID     string `json:"licenseId"`
+	Name          string `json:"name,omitempty"`
+	ExtractedText string `json:"extractedText"`
+}
+
+type spdxRelationship struct {
+	Element        string `json:"spdxElementId"`
+	Type           string `json:"relationshipType"`
+	RelatedElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX returns the document as SPDX 2.2 JSON.  The document namespace is derived from the content of the document so
+// that it is both unique and reproducible.
+func (d Document) SPDX() ([]byte, error) {
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.2",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        d.Name,
+		CreationInfo: spdxCreationInfo{
+			Created:  created().Format("2006-01-02T15:04:05Z"),
+			Creators: []string{"Tool: libhkbuildpack"},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	extracted := make(map[string]spdxLicense)
+	for i, c := range d.Components {
+		p := spdxPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, strings.Trim(spdxInvalid.ReplaceAllString(c.ID, "-"), "-")),
+			Name:             name(c),
+			VersionInfo:      c.Version,
+			DownloadLocation: orNoAssertion(c.URI),
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  orNoAssertion(licenseExpression(c, extracted)),
+			CopyrightText:    noAssertion,
+		}
+
+		for _, s := range c.Checksums {
+			p.Checksums = append(p.Checksums, spdxChecksum{strings.ToUpper(s.Algorithm()), s.Hex()})
+		}
+
+		if c.PURL != "" {
+			p.ExternalRefs = append(p.ExternalRefs, spdxExternalRef{"PACKAGE_MANAGER", "purl", c.PURL})
+		}
+
+		for _, cpe := range c.CPEs {
+			t := "cpe23Type"
+			if strings.HasPrefix(cpe, "cpe:/") {
+				t = "cpe22Type"
+			}
+
+			p.ExternalRefs = append(p.ExternalRefs, spdxExternalRef{"SECURITY", t, cpe})
+		}
+
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{doc.SPDXID, "DESCRIBES", p.SPDXID})
+	}
+
+	for _, l := range extracted {
+		doc.ExtractedLicenses = append(doc.ExtractedLicenses, l)
+	}
+	sort.Slice(doc.ExtractedLicenses, func(i int, j int) bool {
+		return doc.ExtractedLicenses[i].LicenseID < doc.ExtractedLicenses[j].LicenseID
+	})
+
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(content)
+	doc.DocumentNamespace = fmt.Sprintf("%s/%s-%s", SPDXNamespace, spdxInvalid.ReplaceAllString(d.Name, "-"), hex.EncodeToString(digest[:]))
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// licenseExpression returns the declared license expression of a component.  License types that are not valid SPDX
+// expressions of known identifiers are declared as LicenseRef-<type> references.  Every license reference in the
+// expression is added to extracted so that the document can describe it.
+func licenseExpression(component Component, extracted map[string]spdxLicense) string {
+	var types []string
+
+	for _, l := range component.Licenses {
+		if l.Type == "" {
+			continue
+		}
+
+		e, err := l.Expression()
+		if err != nil || len(e.Unknown) > 0 {
+			id := fmt.Sprintf("LicenseRef-%s", strings.Trim(spdxInvalid.ReplaceAllString(l.Type, "-"), "-"))
+			if _, ok := extracted[id]; !ok {
+				extracted[id] = spdxLicense{LicenseID: id, Name: l.Type, ExtractedText: extractedText(l)}
+			}
+
+			types = append(types, id)
+			continue
+		}
+
+		for _, id := range strings.FieldsFunc(e.Normalized, func(r rune) bool { return r == ' ' || r == '(' || r == ')' }) {
+			id = strings.TrimSuffix(id, "+")
+			if _, ok := extracted[id]; !ok && strings.HasPrefix(strings.ToLower(id), "licenseref-") {
+				extracted[id] = spdxLicense{LicenseID: id, ExtractedText: orNoAssertion(l.URI)}
+			}
+		}
+
+		types = append(types, e.Normalized)
+	}
+
+	if len(types) > 1 {
+		for i, t := range types {
+			if strings.Contains(t, " ") {
+				types[i] = fmt.Sprintf("(%s)", t)
+			}
+		}
+	}
+
+	return strings.Join(types, " AND ")
+}
+
+// extractedText describes a license that is not on the SPDX license list.
+func extractedText(license buildpack.License) string {
+	if license.URI != "" {
+		return fmt.Sprintf("%s: %s", license.Type, license.URI)
+	}
+
+	return license.Type
+}
+
+func orNoAssertion(s string) string {
+	if s == "" {
+		return noAssertion
+	}
+
+	return s
+}
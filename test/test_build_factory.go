@@ -148,6 +148,8 @@ func (f *BuildFactory) addDependency(dependency buildpack.Dependency) {
 		"version":  dependency.Version.Version.Original(),
 		"uri":      dependency.URI,
 		"sha256":   dependency.SHA256,
+		"sha512":   dependency.SHA512,
+		"checksum": string(dependency.Checksum),
 		"stacks":   stacks,
 		"licenses": licenses,
 	})
@@ -156,7 +158,7 @@ func (f *BuildFactory) addDependency(dependency buildpack.Dependency) {
 func (f *BuildFactory) cacheFixture(dependency buildpack.Dependency, fixturePath string) {
 	f.t.Helper()
 
-	l := f.Build.Layers.Layer(dependency.SHA256)
+	l := f.Build.Layers.Layer(dependency.CacheKey())
 	if err := helper.CopyFile(fixturePath, filepath.Join(l.Root, filepath.Base(fixturePath))); err != nil {
 		f.t.Fatal(err)
 	}